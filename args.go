@@ -0,0 +1,103 @@
+package mageconfig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ArgsOption configures how Args exports the configuration.
+type ArgsOption func(*argsOptions)
+
+// argsOptions holds the settings of a single Args call.
+type argsOptions struct {
+	changedOnly  bool
+	shellQuoting bool
+}
+
+// WithChangedOnly makes Args export only the fields that were set explicitly, as reported by IsSet,
+// or whose values differ from their default values.
+func WithChangedOnly() ArgsOption {
+	return func(o *argsOptions) {
+		o.changedOnly = true
+	}
+}
+
+// WithShellQuoting makes Args quote the arguments for POSIX shells, e.g. to print a command line.
+// Arguments passed directly to a process, e.g. with exec.Command, must not be quoted.
+func WithShellQuoting() ArgsOption {
+	return func(o *argsOptions) {
+		o.shellQuoting = true
+	}
+}
+
+// Args returns the effective value of each field of the configuration as a command-line argument
+// in the form "--arg-name=value", by the name of its 'arg' tag (or the field name in lower case),
+// formatted as the field would be read from the argument. It allows forwarding the configuration when
+// re-invoking mage or another program built with this package. Fields with empty values, such as empty
+// strings and slices, and fields whose 'sources' tag excludes command-line arguments are omitted. Secret
+// values are included, so beware that command-line arguments may be visible to other users of the system.
+//
+// Since argument values starting with "file://" or "@" are read from files, and secret references of
+// fields that resolve them, such as "cmd://pass show api-key", are resolved, Args returns an error for
+// such values.
+func Args(cfg Config, opts ...ArgsOption) ([]string, error) {
+	cfgValue := reflect.ValueOf(cfg)
+	if cfgValue.Kind() != reflect.Pointer || cfgValue.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("config must be a pointer to a struct")
+	}
+
+	ao := &argsOptions{}
+	for _, opt := range opts {
+		opt(ao)
+	}
+
+	var args []string
+	err := setFields(Snapshot(cfg), func(field reflect.StructField, value reflect.Value) error {
+		if !acceptsSource(field, SourceArg) {
+			return nil
+		}
+		strVal := formatFieldValue(value)
+		if strVal == "" {
+			return nil
+		}
+		if ao.changedOnly && !IsSet(cfg, field.Name) && strVal == formatDefaultValue(field) {
+			return nil
+		}
+		for _, prefix := range []string{filePrefix, fileShortPrefix} {
+			if strings.HasPrefix(strVal, prefix) {
+				return fmt.Errorf("field %s: value starting with %q cannot be passed as an argument", field.Name, prefix)
+			}
+		}
+		if isSecretReference(field, strVal) {
+			return fmt.Errorf("field %s: secret reference cannot be passed as an argument", field.Name)
+		}
+
+		arg := argPrefix + argPrefix + getTagOrDefault(field, tagArg) + "=" + strVal
+		if ao.shellQuoting {
+			arg = shellQuote(arg)
+		}
+		args = append(args, arg)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return args, nil
+}
+
+// formatDefaultValue returns the default value of the field, formatted as by formatFieldValue. It is the
+// zero value of the field if it has no default value, or if the default value is computed from other
+// values or cannot be parsed.
+func formatDefaultValue(field reflect.StructField) string {
+	value := reflect.New(field.Type).Elem()
+	if defaultValue := field.Tag.Get(tagDefault); defaultValue != "" && !strings.Contains(defaultValue, "$") {
+		if err := setFieldByKind(field, value, defaultValue); err != nil {
+			value = reflect.New(field.Type).Elem()
+		}
+	}
+
+	return formatFieldValue(value)
+}
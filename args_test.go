@@ -0,0 +1,118 @@
+package mageconfig
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type argsTestConfig struct {
+	URL     string            `arg:"url"`
+	Retries int               `arg:"retries" default:"3"`
+	Timeout time.Duration     `arg:"timeout" default:"5s"`
+	Tags    []string          `arg:"tags"`
+	Labels  map[string]string `arg:"labels"`
+	Debug   bool              `arg:"debug"`
+	Name    string
+	Token   string `arg:"token" secret:"true"`
+	Region  string `arg:"region" sources:"file"`
+}
+
+func TestArgs(t *testing.T) {
+	cfg := argsTestConfig{
+		URL:     "https://example.com/a b",
+		Retries: 3,
+		Timeout: time.Minute,
+		Tags:    []string{"a", "b"},
+		Labels:  map[string]string{"team": "core", "env": "dev"},
+		Name:    "app",
+		Region:  "eu", // Not read from arguments.
+	}
+
+	tests := []struct {
+		name     string
+		opts     []ArgsOption
+		expected []string
+	}{
+		{
+			name: "All fields",
+			expected: []string{
+				"--url=https://example.com/a b",
+				"--retries=3",
+				"--timeout=1m0s",
+				"--tags=a,b",
+				"--labels=env:dev,team:core",
+				"--debug=false",
+				"--name=app",
+			},
+		},
+		{
+			name: "Changed only",
+			opts: []ArgsOption{WithChangedOnly()},
+			expected: []string{
+				"--url=https://example.com/a b",
+				"--timeout=1m0s",
+				"--tags=a,b",
+				"--labels=env:dev,team:core",
+				"--name=app",
+			},
+		},
+		{
+			name: "Shell quoting",
+			opts: []ArgsOption{WithChangedOnly(), WithShellQuoting()},
+			expected: []string{
+				"'--url=https://example.com/a b'",
+				"--timeout=1m0s",
+				"--tags=a,b",
+				"--labels=env:dev,team:core",
+				"--name=app",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := Args(&cfg, tt.opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, args)
+		})
+	}
+
+	cfg.URL = "@file"
+	_, err := Args(&cfg)
+	assert.EqualError(t, err, `field URL: value starting with "@" cannot be passed as an argument`)
+
+	cfg.URL = "file:///srv/git/repo.git"
+	_, err = Args(&cfg)
+	assert.EqualError(t, err, `field URL: value starting with "file://" cannot be passed as an argument`)
+
+	cfg.URL = "https://example.com"
+	cfg.Token = "cmd://pass show token"
+	_, err = Args(&cfg)
+	assert.EqualError(t, err, "field Token: secret reference cannot be passed as an argument")
+}
+
+func TestArgsRoundTrip(t *testing.T) {
+	cfg := argsTestConfig{
+		URL:     "https://example.com/a b",
+		Retries: -1,
+		Timeout: time.Minute,
+		Tags:    []string{"a", "b"},
+		Labels:  map[string]string{"team": "core"},
+		Debug:   true,
+		Name:    "app",
+	}
+
+	args, err := Args(&cfg)
+	assert.NoError(t, err)
+
+	// Beware: this modifies global state and is not safe for parallel test execution.
+	os.Args = append([]string{"cmd"}, args...)
+	defer func() { isLoaded = false }()
+
+	loaded := argsTestConfig{}
+	assert.NoError(t, Load(&loaded, ""))
+	assert.Equal(t, cfg, loaded)
+}
@@ -0,0 +1,225 @@
+package mageconfig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSchema is the error returned when the tags of a configuration struct are inconsistent.
+var ErrInvalidSchema = errors.New("invalid configuration schema")
+
+// Check validates the definition of a configuration struct without reading any source. It reports
+// references to unknown or unloadable fields in the 'depends', 'conflicts', 'requiredwith' and
+// 'requiredif' tags, a 'fileenv' tag without an 'env' tag, git keys without a section, duplicate
+// argument, environment variable, file parameter and git key names, unparseable default values or
+// references in them, invalid 'enum', 'min' and 'max' tags, dependency cycles and unsupported field
+// types. It is called by Load, and can also be called from a unit test to catch mistakes early:
+//
+//	func TestConfig(t *testing.T) {
+//		if err := mageconfig.Check(&Config{}); err != nil {
+//			t.Fatal(err)
+//		}
+//	}
+func Check(cfg Config) error {
+	cfgType := reflect.TypeOf(cfg)
+	if cfgType == nil || cfgType.Kind() != reflect.Pointer || cfgType.Elem().Kind() != reflect.Struct {
+		return errors.New("config must be a pointer to a struct")
+	}
+	cfgType = cfgType.Elem()
+
+	var errs []error
+	names := map[string]map[string]string{tagArg: {}, tagEnv: {}, tagFile: {}, tagGit: {}}
+	for i := 0; i < cfgType.NumField(); i++ {
+		field := cfgType.Field(i)
+		if !isLoadable(field) {
+			continue
+		}
+
+		if !isSupportedType(field.Type) {
+			errs = append(errs, fmt.Errorf("field %s: unsupported type %s", field.Name, field.Type))
+			continue
+		}
+
+		// Names must be unique for each source.
+		for _, tag := range []string{tagArg, tagEnv, tagFile, tagGit} {
+			name := field.Tag.Get(tag)
+			switch tag {
+			case tagArg:
+				name = getTagOrDefault(field, tagArg)
+			case tagGit:
+				name = normalizeGitKey(name)
+			}
+			if name == "" {
+				continue
+			}
+			if other, ok := names[tag][name]; ok {
+				errs = append(errs, fmt.Errorf("field %s: duplicate %s name %q (also used by %s)", field.Name, tag, name, other))
+				continue
+			}
+			names[tag][name] = field.Name
+		}
+
+		if key := field.Tag.Get(tagGit); key != "" && strings.Count(key, ".") == 0 {
+			errs = append(errs, fmt.Errorf("field %s: invalid git key %q: missing section", field.Name, key))
+		}
+
+		if field.Tag.Get(tagFileEnv) == "true" && field.Tag.Get(tagEnv) == "" {
+			errs = append(errs, fmt.Errorf("field %s: %s tag requires %s tag", field.Name, tagFileEnv, tagEnv))
+		}
+
+		// References to other fields must point to existing fields that are loaded from the sources.
+		for _, tag := range []string{tagDepends, tagConflicts, tagRequiredWith} {
+			refs := field.Tag.Get(tag)
+			if refs == "" {
+				continue
+			}
+			for _, ref := range strings.Split(refs, ",") {
+				if _, err := lookupLoadableField(cfgType, ref); err != nil {
+					errs = append(errs, fmt.Errorf("field %s: %w in %s tag", field.Name, err, tag))
+				}
+			}
+		}
+
+		if requiredIf := field.Tag.Get(tagRequiredIf); requiredIf != "" {
+			if _, err := matchCondition(reflect.New(cfgType).Elem(), requiredIf); err != nil {
+				errs = append(errs, fmt.Errorf("field %s: %w", field.Name, err))
+			}
+		}
+
+		// Allowed values and ranges must be parseable into the field type.
+		if _, err := parseEnum(field); err != nil {
+			errs = append(errs, err)
+		}
+		if _, _, err := parseRange(field); err != nil {
+			errs = append(errs, err)
+		}
+
+		// Default values must be parseable into the field type, unless they are secret references.
+		// References in default values must be well-formed and point to existing fields.
+		defaultValue := field.Tag.Get(tagDefault)
+		switch {
+		case strings.Contains(defaultValue, "$"):
+			_, err := expandString(defaultValue, func(name string) (string, error) {
+				_, err := lookupLoadableField(cfgType, name)
+				return "", err
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("field %s: invalid default value: %w", field.Name, err))
+			}
+		case defaultValue != "" && !isSecretReference(field, defaultValue):
+			value := reflect.New(field.Type).Elem()
+			if err := setFieldByKind(field, value, defaultValue); err != nil {
+				errs = append(errs, fmt.Errorf("field %s: invalid default value: %w", field.Name, err))
+			}
+		}
+	}
+
+	if err := checkDependsCycles(cfgType); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidSchema, errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// isLoadable reports whether the field is populated from the configuration sources. Unexported fields
+// and nested structs (other than time.Time) are not loaded, though nested structs are still validated.
+func isLoadable(field reflect.StructField) bool {
+	if !field.IsExported() {
+		return false
+	}
+
+	return field.Type.Kind() != reflect.Struct || field.Type == reflect.TypeOf(time.Time{})
+}
+
+// lookupLoadableField returns the field of the struct type with the given name. Only loadable fields can
+// be referenced by tags and values, since other fields are never set.
+func lookupLoadableField(cfgType reflect.Type, name string) (reflect.StructField, error) {
+	field, ok := cfgType.FieldByName(name)
+	if !ok {
+		return reflect.StructField{}, fmt.Errorf("unknown field %q", name)
+	}
+	if len(field.Index) != 1 || !isLoadable(field) {
+		return reflect.StructField{}, fmt.Errorf("unloadable field %q", name)
+	}
+
+	return field, nil
+}
+
+// isSupportedType reports whether values of the type can be parsed by setFieldByKind.
+func isSupportedType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Slice:
+		return isSupportedBasicType(t.Elem())
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String && isSupportedBasicType(t.Elem())
+	}
+
+	return isSupportedBasicType(t)
+}
+
+// isSupportedBasicType reports whether values of the type can be parsed by parseStringToType.
+func isSupportedBasicType(t reflect.Type) bool {
+	if t == reflect.TypeOf(time.Duration(0)) || t == reflect.TypeOf(time.Time{}) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	}
+
+	return false
+}
+
+// checkDependsCycles returns an error if the 'depends' tags of the configuration struct form a cycle.
+func checkDependsCycles(cfgType reflect.Type) error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int)
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("dependency cycle: %s", strings.Join(append(path, name), " -> "))
+		case visited:
+			return nil
+		}
+
+		field, err := lookupLoadableField(cfgType, name)
+		if err != nil {
+			return nil // Unknown and unloadable fields are reported separately.
+		}
+
+		state[name] = visiting
+		if dependsStr := field.Tag.Get(tagDepends); dependsStr != "" {
+			for _, depend := range strings.Split(dependsStr, ",") {
+				if err := visit(depend, append(path, name)); err != nil {
+					return err
+				}
+			}
+		}
+		state[name] = visited
+
+		return nil
+	}
+
+	for i := 0; i < cfgType.NumField(); i++ {
+		if err := visit(cfgType.Field(i).Name, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
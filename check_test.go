@@ -0,0 +1,162 @@
+package mageconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	type validConfig struct {
+		URL       string        `arg:"url" env:"URL" file:"url" required:"true"`
+		BackupURL string        `arg:"backup-url" depends:"URL"`
+		Timeout   time.Duration `default:"5s"`
+		Tags      []string      `default:"a,b"`
+		Limits    map[string]int
+		Nested    struct{ Name string }
+		internal  chan int //nolint:unused
+	}
+
+	type unknownDepends struct {
+		DatabaseURL string
+		BackupURL   string `depends:"DatabseURL"`
+		Token       string `conflicts:"Pasword"`
+		Cert        string `requiredif:"Mod=prod"`
+		URL         string `default:"https://${field:DatabseURL}"`
+	}
+
+	type unloadableReferences struct {
+		URL    string `depends:"mode"`
+		Token  string `requiredwith:"TLS"`
+		Cert   string `requiredif:"mode=prod"`
+		Backup string `default:"${field:TLS}"`
+		TLS    struct{ Cert string }
+		mode   string //nolint:unused
+	}
+
+	type duplicateNames struct {
+		A string `arg:"name" env:"NAME" file:"name"`
+		B string `arg:"name" env:"NAME" file:"name"`
+	}
+
+	type invalidDefault struct {
+		Count   int           `default:"many"`
+		Timeout time.Duration `default:"soon"`
+	}
+
+	type sizedTypes struct {
+		Count   int32              `default:"5" min:"1"`
+		Size    uint64             `default:"1024"`
+		Ratio   float32            `default:"0.5" max:"1"`
+		Weights map[string]float32 `default:"a:0.5,b:1"`
+		Ports   []uint32           `default:"80,443"`
+		Small   int32              `default:"4294967296"`
+	}
+
+	type dependsCycle struct {
+		A string `depends:"B"`
+		B string `depends:"C"`
+		C string `depends:"A"`
+	}
+
+	type invalidConstraints struct {
+		Mode    string        `enum:"dev,prod"`
+		Count   int           `enum:"1,two" min:"5" max:"1"`
+		Name    string        `min:"a"`
+		Timeout time.Duration `min:"1s" max:"1m"`
+	}
+
+	type unsupportedType struct {
+		Ch    chan int
+		Bytes [][]byte
+		Map   map[int]string
+	}
+
+	testCases := []struct {
+		name    string
+		cfg     Config
+		wantErr string
+	}{
+		{
+			name: "valid configuration",
+			cfg:  &validConfig{},
+		},
+		{
+			name:    "not a pointer",
+			cfg:     validConfig{},
+			wantErr: "config must be a pointer to a struct",
+		},
+		{
+			name: "unknown references",
+			cfg:  &unknownDepends{},
+			wantErr: "invalid configuration schema: " +
+				"field BackupURL: unknown field \"DatabseURL\" in depends tag\n" +
+				"field Token: unknown field \"Pasword\" in conflicts tag\n" +
+				"field Cert: unknown field \"Mod\" in condition: Mod=prod\n" +
+				"field URL: invalid default value: unknown field \"DatabseURL\"",
+		},
+		{
+			name: "unloadable references",
+			cfg:  &unloadableReferences{},
+			wantErr: "invalid configuration schema: " +
+				"field URL: unloadable field \"mode\" in depends tag\n" +
+				"field Token: unloadable field \"TLS\" in requiredwith tag\n" +
+				"field Cert: unloadable field \"mode\" in condition: mode=prod\n" +
+				"field Backup: invalid default value: unloadable field \"TLS\"",
+		},
+		{
+			name: "duplicate names",
+			cfg:  &duplicateNames{},
+			wantErr: "invalid configuration schema: " +
+				"field B: duplicate arg name \"name\" (also used by A)\n" +
+				"field B: duplicate env name \"NAME\" (also used by A)\n" +
+				"field B: duplicate file name \"name\" (also used by A)",
+		},
+		{
+			name: "invalid default values",
+			cfg:  &invalidDefault{},
+			wantErr: "invalid configuration schema: " +
+				"field Count: invalid default value: parse field: strconv.ParseInt: parsing \"many\": invalid syntax\n" +
+				"field Timeout: invalid default value: parse field: time: invalid duration \"soon\"",
+		},
+		{
+			name: "sized numeric types",
+			cfg:  &sizedTypes{},
+			wantErr: "invalid configuration schema: " +
+				"field Small: invalid default value: parse field: strconv.ParseInt: parsing \"4294967296\": value out of range",
+		},
+		{
+			name:    "dependency cycle",
+			cfg:     &dependsCycle{},
+			wantErr: "invalid configuration schema: dependency cycle: A -> B -> C -> A",
+		},
+		{
+			name: "invalid constraints",
+			cfg:  &invalidConstraints{},
+			wantErr: "invalid configuration schema: " +
+				"field Count: invalid enum value \"two\": strconv.ParseInt: parsing \"two\": invalid syntax\n" +
+				"field Count: min value greater than max value\n" +
+				"field Name: min tag requires a numeric or duration type",
+		},
+		{
+			name: "unsupported types",
+			cfg:  &unsupportedType{},
+			wantErr: "invalid configuration schema: " +
+				"field Ch: unsupported type chan int\n" +
+				"field Bytes: unsupported type [][]uint8\n" +
+				"field Map: unsupported type map[int]string",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Check(tc.cfg)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
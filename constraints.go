@@ -0,0 +1,96 @@
+package mageconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// elemType returns the type of the elements of slice and map types, or the type itself.
+func elemType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+		return t.Elem()
+	}
+
+	return t
+}
+
+// parseEnum returns the allowed values of the field from its 'enum' tag, parsed and formatted as by
+// formatBasicValue, or nil if the field has no 'enum' tag.
+func parseEnum(field reflect.StructField) ([]string, error) {
+	enumStr := field.Tag.Get(tagEnum)
+	if enumStr == "" {
+		return nil, nil
+	}
+
+	var enum []string
+	for _, s := range strings.Split(enumStr, sliceSeparator) {
+		v, err := parseStringToType(strings.TrimSpace(s), elemType(field.Type))
+		if err != nil {
+			return nil, fmt.Errorf("field %s: invalid %s value %q: %w", field.Name, tagEnum, s, err)
+		}
+		enum = append(enum, formatBasicValue(v))
+	}
+
+	return enum, nil
+}
+
+// parseRange returns the bounds of the field from its 'min' and 'max' tags, as numbers comparable with
+// numericValue, or nil for missing bounds.
+func parseRange(field reflect.StructField) (minValue, maxValue *float64, err error) {
+	parse := func(tag string) (*float64, error) {
+		s := field.Tag.Get(tag)
+		if s == "" {
+			return nil, nil
+		}
+
+		v, err := parseStringToType(s, elemType(field.Type))
+		if err != nil {
+			return nil, fmt.Errorf("field %s: invalid %s value %q: %w", field.Name, tag, s, err)
+		}
+		number, ok := numericValue(v)
+		if !ok {
+			return nil, fmt.Errorf("field %s: %s tag requires a numeric or duration type", field.Name, tag)
+		}
+		return &number, nil
+	}
+
+	if minValue, err = parse(tagMin); err != nil {
+		return nil, nil, err
+	}
+	if maxValue, err = parse(tagMax); err != nil {
+		return nil, nil, err
+	}
+	if minValue != nil && maxValue != nil && *minValue > *maxValue {
+		return nil, nil, fmt.Errorf("field %s: %s value greater than %s value", field.Name, tagMin, tagMax)
+	}
+
+	return minValue, maxValue, nil
+}
+
+// numericValue returns the value of a number or duration as a float64, and whether it is one.
+func numericValue(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+
+	return 0, false
+}
+
+// describeRange returns the range of the field from its 'min' and 'max' tags, such as "min 1, max 10".
+func describeRange(field reflect.StructField) string {
+	var bounds []string
+	if minStr := field.Tag.Get(tagMin); minStr != "" {
+		bounds = append(bounds, "min "+minStr)
+	}
+	if maxStr := field.Tag.Get(tagMax); maxStr != "" {
+		bounds = append(bounds, "max "+maxStr)
+	}
+
+	return strings.Join(bounds, ", ")
+}
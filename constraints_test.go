@@ -0,0 +1,84 @@
+package mageconfig
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEnum(t *testing.T) {
+	type testConfig struct {
+		Mode  string        `enum:"dev, prod"`
+		Ratio []float64     `enum:"0.50,1"`
+		Wait  time.Duration `enum:"60s,2m"`
+		Count int           `enum:"1,two"`
+		Name  string
+	}
+
+	testCases := []struct {
+		field   string
+		want    []string
+		wantErr string
+	}{
+		{field: "Mode", want: []string{"dev", "prod"}},
+		{field: "Ratio", want: []string{"0.5", "1"}},
+		{field: "Wait", want: []string{"1m0s", "2m0s"}},
+		{field: "Count", wantErr: `field Count: invalid enum value "two": strconv.ParseInt: parsing "two": invalid syntax`},
+		{field: "Name"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.field, func(t *testing.T) {
+			field, _ := reflect.TypeOf(testConfig{}).FieldByName(tc.field)
+			enum, err := parseEnum(field)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, enum)
+		})
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	type testConfig struct {
+		Retries int            `min:"1" max:"10"`
+		Timeout time.Duration  `min:"1s"`
+		Levels  map[string]int `max:"3"`
+		Count   int            `min:"5" max:"1"`
+		Name    string         `min:"a"`
+	}
+
+	float := func(v float64) *float64 { return &v }
+	testCases := []struct {
+		field     string
+		wantMin   *float64
+		wantMax   *float64
+		wantRange string
+		wantErr   string
+	}{
+		{field: "Retries", wantMin: float(1), wantMax: float(10), wantRange: "min 1, max 10"},
+		{field: "Timeout", wantMin: float(float64(time.Second)), wantRange: "min 1s"},
+		{field: "Levels", wantMax: float(3), wantRange: "max 3"},
+		{field: "Count", wantErr: "field Count: min value greater than max value"},
+		{field: "Name", wantErr: "field Name: min tag requires a numeric or duration type"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.field, func(t *testing.T) {
+			field, _ := reflect.TypeOf(testConfig{}).FieldByName(tc.field)
+			minValue, maxValue, err := parseRange(field)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantMin, minValue)
+			assert.Equal(t, tc.wantMax, maxValue)
+			assert.Equal(t, tc.wantRange, describeRange(field))
+		})
+	}
+}
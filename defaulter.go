@@ -0,0 +1,57 @@
+package mageconfig
+
+import "reflect"
+
+// Defaulter is an optional interface that configuration structs (and structs nested in them) can implement
+// to compute default values that depend on other fields, such as a backup URL derived from the main one.
+// SetDefaults is called once all sources are loaded and references are expanded, so it sees the final
+// values and can check for zero values to fill in only the fields that were not set explicitly.
+// Fields changed by SetDefaults are considered set by the required and dependency checks.
+type Defaulter interface {
+	SetDefaults()
+}
+
+// applyDefaults calls the SetDefaults method of every nested struct and then of the configuration struct
+// itself, and marks the fields of the configuration struct changed by them as set and computed.
+func applyDefaults(cfg Config, isSet map[string]*bool, raw map[string]*rawValue) {
+	cfgValue := reflect.ValueOf(cfg).Elem()
+
+	// Keep a copy of the configuration to find the fields changed by SetDefaults.
+	before := reflect.New(cfgValue.Type()).Elem()
+	before.Set(cfgValue)
+
+	applyDefaultsValue(cfgValue.Addr())
+
+	_ = setFields(cfg, func(field reflect.StructField, value reflect.Value) error {
+		if !reflect.DeepEqual(value.Interface(), before.FieldByIndex(field.Index).Interface()) {
+			*isSet[field.Name] = true
+			raw[field.Name] = &rawValue{origin: Origin{Source: SourceComputed}}
+		}
+		return nil
+	})
+}
+
+// applyDefaultsValue recursively calls SetDefaults on the struct pointed to by v, nested structs first.
+func applyDefaultsValue(v reflect.Value) {
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+
+	elem := v.Elem()
+	for i := 0; i < elem.NumField(); i++ {
+		field := elem.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		value := elem.Field(i)
+		if value.Kind() == reflect.Struct {
+			value = value.Addr()
+		}
+		applyDefaultsValue(value)
+	}
+
+	if defaulter, ok := v.Interface().(Defaulter); ok {
+		defaulter.SetDefaults()
+	}
+}
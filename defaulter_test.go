@@ -0,0 +1,74 @@
+package mageconfig
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPoolConfig struct {
+	Size int
+}
+
+func (c *testPoolConfig) SetDefaults() {
+	if c.Size == 0 {
+		c.Size = 10
+	}
+}
+
+type testDefaulterConfig struct {
+	DatabaseURL       string `arg:"db-url" required:"true"`
+	BackupDatabaseURL string `arg:"backup-db-url"`
+	BackupName        string `arg:"backup-name" default:"${field:BackupDatabaseURL}/name"`
+	Archive           string `arg:"archive" depends:"BackupDatabaseURL"`
+	Pool              testPoolConfig
+}
+
+func (c *testDefaulterConfig) SetDefaults() {
+	if c.BackupDatabaseURL == "" {
+		c.BackupDatabaseURL = c.DatabaseURL + "/backup"
+	}
+}
+
+func TestLoadDefaulter(t *testing.T) {
+	testCases := []struct {
+		name       string
+		args       []string
+		wantConfig testDefaulterConfig
+	}{
+		{
+			name: "computed defaults",
+			args: []string{"-db-url=postgres://db"},
+			wantConfig: testDefaulterConfig{
+				DatabaseURL:       "postgres://db",
+				BackupDatabaseURL: "postgres://db/backup",
+				BackupName:        "/name", // References are expanded before SetDefaults is called.
+				Pool:              testPoolConfig{Size: 10},
+			},
+		},
+		{
+			name: "explicit values are kept",
+			args: []string{"-db-url=postgres://db", "-backup-db-url=postgres://backup"},
+			wantConfig: testDefaulterConfig{
+				DatabaseURL:       "postgres://db",
+				BackupDatabaseURL: "postgres://backup",
+				BackupName:        "postgres://backup/name",
+				Pool:              testPoolConfig{Size: 10},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Beware: this modifies global state and is not safe for parallel test execution.
+			os.Args = append([]string{"cmd"}, tc.args...)
+			defer func() { isLoaded = false }()
+
+			cfg := testDefaulterConfig{}
+			// The dependency of Archive is satisfied by the value computed by SetDefaults.
+			assert.NoError(t, Load(&cfg, ""))
+			assert.Equal(t, tc.wantConfig, cfg)
+		})
+	}
+}
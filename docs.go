@@ -0,0 +1,205 @@
+package mageconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// docField describes a configuration field for the documentation generators.
+type docField struct {
+	name        string
+	file        string
+	env         string
+	arg         string
+	git         string
+	typeName    string
+	defaultStr  string
+	required    bool
+	depends     []string
+	description string
+}
+
+// docFields returns the descriptions of the loadable fields of the configuration struct.
+func docFields(cfg Config) ([]docField, error) {
+	cfgType := reflect.TypeOf(cfg)
+	if cfgType == nil || cfgType.Kind() != reflect.Pointer || cfgType.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("config must be a pointer to a struct")
+	}
+	cfgType = cfgType.Elem()
+
+	var fields []docField
+	for i := 0; i < cfgType.NumField(); i++ {
+		field := cfgType.Field(i)
+		if !isLoadable(field) {
+			continue
+		}
+
+		defaultStr := field.Tag.Get(tagDefault)
+		if defaultStr != "" && isSecret(field) {
+			defaultStr = secretMask
+		}
+		var depends []string
+		if dependsStr := field.Tag.Get(tagDepends); dependsStr != "" {
+			depends = strings.Split(dependsStr, ",")
+		}
+
+		fields = append(fields, docField{
+			name:        field.Name,
+			file:        field.Tag.Get(tagFile),
+			env:         field.Tag.Get(tagEnv),
+			arg:         argPrefix + argPrefix + getTagOrDefault(field, tagArg),
+			git:         field.Tag.Get(tagGit),
+			typeName:    describeType(field.Type),
+			defaultStr:  defaultStr,
+			required:    field.Tag.Get(tagRequired) == "true",
+			depends:     depends,
+			description: field.Tag.Get(tagDesc),
+		})
+	}
+
+	return fields, nil
+}
+
+// GenerateMarkdown returns a Markdown reference of the configuration struct: a table with the names of
+// each field in the configuration file, environment variables, command-line arguments and git
+// configuration (if used), its type, default value, whether it is required, the fields it depends on,
+// and its description. The output only depends on the struct, so it is suitable for a "go generate"
+// step that keeps the documentation in sync with the struct.
+func GenerateMarkdown(cfg Config) ([]byte, error) {
+	fields, err := docFields(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	useGit := false
+	for _, f := range fields {
+		useGit = useGit || f.git != ""
+	}
+
+	header := []string{"Field", "File", "Environment", "Argument"}
+	if useGit {
+		header = append(header, "Git")
+	}
+	header = append(header, "Type", "Default", "Required", "Depends", "Description")
+
+	var b bytes.Buffer
+	writeRow := func(cells []string) {
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	writeRow(header)
+	separators := make([]string, len(header))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	writeRow(separators)
+
+	for _, f := range fields {
+		cells := []string{markdownCode(f.name), markdownCode(f.file), markdownCode(f.env), markdownCode(f.arg)}
+		if useGit {
+			cells = append(cells, markdownCode(f.git))
+		}
+		required := ""
+		if f.required {
+			required = "yes"
+		}
+		depends := make([]string, len(f.depends))
+		for i, depend := range f.depends {
+			depends[i] = markdownCode(depend)
+		}
+		cells = append(cells, markdownText(f.typeName), markdownCode(f.defaultStr), required,
+			strings.Join(depends, ", "), markdownText(f.description))
+		writeRow(cells)
+	}
+
+	return b.Bytes(), nil
+}
+
+// markdownCode formats the text as inline code in a Markdown table cell, or returns an empty string.
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+
+	return "`" + strings.ReplaceAll(s, "|", `\|`) + "`"
+}
+
+// markdownText escapes the text for a Markdown table cell.
+func markdownText(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", `\|`), "\n", " ")
+}
+
+// GenerateManPage returns a roff man page, with the given name and section, that documents the
+// configuration struct: for each field, its names in the configuration file, environment variables,
+// command-line arguments and git configuration, its type, default value, whether it is required, the
+// fields it depends on, and its description. The page has no date, so that it only depends on the
+// struct, which makes it suitable for a "go generate" step.
+func GenerateManPage(cfg Config, name, section string) ([]byte, error) {
+	fields, err := docFields(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, ".TH %s %s\n", roffEscape(strings.ToUpper(name)), roffEscape(section))
+	b.WriteString(".SH NAME\n")
+	fmt.Fprintf(&b, "%s \\- configuration parameters\n", roffEscape(name))
+	b.WriteString(".SH DESCRIPTION\n")
+	b.WriteString("By default, the configuration is read from the default values, the configuration file, " +
+		"the git configuration, environment variables and command-line arguments, " +
+		"in this order of increasing precedence.\n")
+	b.WriteString(".SH PARAMETERS\n")
+
+	for _, f := range fields {
+		b.WriteString(".TP\n")
+		fmt.Fprintf(&b, ".B %s\n", roffEscape(f.name))
+		if f.description != "" {
+			b.WriteString(roffText(f.description) + "\n")
+		}
+		b.WriteString(".RS\n")
+
+		var names []string
+		for _, n := range []struct{ label, value string }{
+			{"File", f.file}, {"Environment", f.env}, {"Argument", f.arg}, {"Git", f.git},
+		} {
+			if n.value != "" {
+				names = append(names, n.label+": \\fB"+roffEscape(n.value)+"\\fR")
+			}
+		}
+		b.WriteString(".PP\n" + strings.Join(names, ", ") + "\n")
+
+		b.WriteString(".br\nType: " + roffEscape(f.typeName) + "\n")
+		if f.defaultStr != "" {
+			b.WriteString(".br\nDefault: \\fB" + roffEscape(f.defaultStr) + "\\fR\n")
+		}
+		if f.required {
+			b.WriteString(".br\nRequired.\n")
+		}
+		if len(f.depends) > 0 {
+			b.WriteString(".br\nDepends on: " + roffEscape(strings.Join(f.depends, ", ")) + "\n")
+		}
+		b.WriteString(".RE\n")
+	}
+
+	return b.Bytes(), nil
+}
+
+// roffEscape escapes backslashes and dashes for roff.
+func roffEscape(s string) string {
+	return strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(s)
+}
+
+// roffText escapes the text for a roff paragraph, so that lines starting with a dot or an apostrophe
+// are not interpreted as requests.
+func roffText(s string) string {
+	lines := strings.Split(roffEscape(s), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,96 @@
+package mageconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type docsTestConfig struct {
+	URL       string `file:"dbURL" env:"DB_URL" arg:"db-url" required:"true" desc:"Database URL"`
+	BackupURL string `file:"backupDBURL" depends:"URL" desc:"Backup | URL"`
+	Retries   int    `env:"RETRIES" default:"3"`
+	APIKey    string `arg:"api-key" default:"abc" secret:"true" desc:".hidden"`
+}
+
+func TestGenerateMarkdown(t *testing.T) {
+	markdown, err := GenerateMarkdown(&docsTestConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, ""+
+		"| Field | File | Environment | Argument | Type | Default | Required | Depends | Description |\n"+
+		"| --- | --- | --- | --- | --- | --- | --- | --- | --- |\n"+
+		"| `URL` | `dbURL` | `DB_URL` | `--db-url` | String |  | yes |  | Database URL |\n"+
+		"| `BackupURL` | `backupDBURL` |  | `--backupurl` | String |  |  | `URL` | Backup \\| URL |\n"+
+		"| `Retries` |  | `RETRIES` | `--retries` | Integer | `3` |  |  |  |\n"+
+		"| `APIKey` |  |  | `--api-key` | String | `******` |  |  | .hidden |\n", string(markdown))
+
+	type gitConfig struct {
+		Registry string `git:"mage.registry"`
+	}
+	markdown, err = GenerateMarkdown(&gitConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, ""+
+		"| Field | File | Environment | Argument | Git | Type | Default | Required | Depends | Description |\n"+
+		"| --- | --- | --- | --- | --- | --- | --- | --- | --- | --- |\n"+
+		"| `Registry` |  |  | `--registry` | `mage.registry` | String |  |  |  |  |\n", string(markdown))
+
+	_, err = GenerateMarkdown(docsTestConfig{})
+	assert.EqualError(t, err, "config must be a pointer to a struct")
+}
+
+func TestGenerateManPage(t *testing.T) {
+	page, err := GenerateManPage(&docsTestConfig{}, "mage-app", "5")
+	assert.NoError(t, err)
+	assert.Equal(t, ""+
+		".TH MAGE\\-APP 5\n"+
+		".SH NAME\n"+
+		"mage\\-app \\- configuration parameters\n"+
+		".SH DESCRIPTION\n"+
+		"By default, the configuration is read from the default values, the configuration file, "+
+		"the git configuration, environment variables and command-line arguments, "+
+		"in this order of increasing precedence.\n"+
+		".SH PARAMETERS\n"+
+		".TP\n"+
+		".B URL\n"+
+		"Database URL\n"+
+		".RS\n"+
+		".PP\n"+
+		"File: \\fBdbURL\\fR, Environment: \\fBDB_URL\\fR, Argument: \\fB\\-\\-db\\-url\\fR\n"+
+		".br\n"+
+		"Type: String\n"+
+		".br\n"+
+		"Required.\n"+
+		".RE\n"+
+		".TP\n"+
+		".B BackupURL\n"+
+		"Backup | URL\n"+
+		".RS\n"+
+		".PP\n"+
+		"File: \\fBbackupDBURL\\fR, Argument: \\fB\\-\\-backupurl\\fR\n"+
+		".br\n"+
+		"Type: String\n"+
+		".br\n"+
+		"Depends on: URL\n"+
+		".RE\n"+
+		".TP\n"+
+		".B Retries\n"+
+		".RS\n"+
+		".PP\n"+
+		"Environment: \\fBRETRIES\\fR, Argument: \\fB\\-\\-retries\\fR\n"+
+		".br\n"+
+		"Type: Integer\n"+
+		".br\n"+
+		"Default: \\fB3\\fR\n"+
+		".RE\n"+
+		".TP\n"+
+		".B APIKey\n"+
+		"\\&.hidden\n"+
+		".RS\n"+
+		".PP\n"+
+		"Argument: \\fB\\-\\-api\\-key\\fR\n"+
+		".br\n"+
+		"Type: String\n"+
+		".br\n"+
+		"Default: \\fB******\\fR\n"+
+		".RE\n", string(page))
+}
@@ -0,0 +1,102 @@
+package mageconfig
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// DumpOption configures how Dump writes the configuration.
+type DumpOption func(*dumpOptions)
+
+// dumpOptions holds the settings of a single Dump call.
+type dumpOptions struct {
+	origins bool
+}
+
+// WithOrigins makes Dump annotate each value with where it came from, as reported by Origins.
+func WithOrigins() DumpOption {
+	return func(o *dumpOptions) {
+		o.origins = true
+	}
+}
+
+// dumpValue is the value of a field with its origin, written by Dump in the JSON format with origins.
+type dumpValue struct {
+	Value  interface{} `json:"value"`
+	Source string      `json:"source"`
+}
+
+// Dump writes the effective configuration to w in the given format, with secret values redacted:
+// FormatTable writes an aligned table of the field names and values, FormatNative, FormatYAML and
+// FormatJSON write the values by the names of their 'file' tags (or their field names if they have none),
+// and FormatShell writes "export VAR=value" lines for the fields with an 'env' tag, to be evaluated by
+// a shell. It replaces hand-written targets that show the configuration.
+//
+// With the WithOrigins option, each value is annotated with where it came from: in a column of the
+// table, in a comment before the value in the native, YAML and shell formats, and as an object with
+// the "value" and "source" keys in the JSON format.
+func Dump(cfg Config, w io.Writer, format Format, opts ...DumpOption) error {
+	cfgValue := reflect.ValueOf(cfg)
+	if cfgValue.Kind() != reflect.Pointer || cfgValue.Elem().Kind() != reflect.Struct {
+		return errors.New("config must be a pointer to a struct")
+	}
+
+	do := &dumpOptions{}
+	for _, opt := range opts {
+		opt(do)
+	}
+
+	fieldOrigins := Origins(cfg)
+	redacted := Redacted(cfg)
+	if format == FormatTable {
+		return writeTable(w, redacted, fieldOrigins, do.origins)
+	}
+
+	var values []keyValue
+	err := setFields(redacted, func(field reflect.StructField, value reflect.Value) error {
+		key := field.Tag.Get(tagFile)
+		if format == FormatShell {
+			key = field.Tag.Get(tagEnv)
+			if key == "" {
+				return nil
+			}
+		} else if key == "" {
+			key = field.Name
+		}
+
+		kv := keyValue{key: key, value: value}
+		if do.origins {
+			kv.comment = fieldOrigins[field.Name].String()
+			if format == FormatJSON {
+				kv.value = reflect.ValueOf(dumpValue{Value: fieldData(value), Source: kv.comment})
+			}
+		}
+		values = append(values, kv)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var content []byte
+	switch format {
+	case FormatNative:
+		content = encodeNative(values)
+	case FormatShell:
+		content = encodeShell(values)
+	case FormatJSON:
+		content, err = encodeJSON(values)
+	case FormatYAML:
+		content, err = encodeYAML(values)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(content)
+	return err
+}
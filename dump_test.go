@@ -0,0 +1,169 @@
+package mageconfig
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDump(t *testing.T) {
+	type testConfig struct {
+		URL     string        `file:"url" env:"URL"`
+		Tags    []string      `file:"tags" env:"TAGS" default:"a,b"`
+		Timeout time.Duration `env:"TIMEOUT" default:"5s"`
+		APIKey  string        `file:"apiKey" env:"API_KEY" secret:"true"`
+		Debug   bool          `arg:"debug"`
+	}
+
+	// Beware: this modifies global state and is not safe for parallel test execution.
+	os.Args = []string{"cmd", "--debug"}
+	defer func() { isLoaded = false }()
+	t.Setenv("URL", "https://example.com/it's")
+	t.Setenv("API_KEY", "abc123")
+
+	cfg := testConfig{}
+	assert.NoError(t, Load(&cfg, ""))
+
+	tests := []struct {
+		name     string
+		format   Format
+		opts     []DumpOption
+		expected string
+	}{
+		{
+			name:   "Table",
+			format: FormatTable,
+			expected: "" +
+				"FIELD    VALUE\n" +
+				"URL      https://example.com/it's\n" +
+				"Tags     [a b]\n" +
+				"Timeout  5s\n" +
+				"APIKey   ******\n" +
+				"Debug    true\n",
+		},
+		{
+			name:   "Table with origins",
+			format: FormatTable,
+			opts:   []DumpOption{WithOrigins()},
+			expected: "" +
+				"FIELD    VALUE                     SOURCE\n" +
+				"URL      https://example.com/it's  env URL\n" +
+				"Tags     [a b]                     default\n" +
+				"Timeout  5s                        default\n" +
+				"APIKey   ******                    env API_KEY\n" +
+				"Debug    true                      arg --debug\n",
+		},
+		{
+			name:   "Native",
+			format: FormatNative,
+			expected: "" +
+				"url: https://example.com/it's\n" +
+				"tags: a,b\n" +
+				"Timeout: 5s\n" +
+				"apiKey: ******\n" +
+				"Debug: true\n",
+		},
+		{
+			name:   "Native with origins",
+			format: FormatNative,
+			opts:   []DumpOption{WithOrigins()},
+			expected: "" +
+				"# env URL\n" +
+				"url: https://example.com/it's\n" +
+				"# default\n" +
+				"tags: a,b\n" +
+				"# default\n" +
+				"Timeout: 5s\n" +
+				"# env API_KEY\n" +
+				"apiKey: ******\n" +
+				"# arg --debug\n" +
+				"Debug: true\n",
+		},
+		{
+			name:   "YAML with origins",
+			format: FormatYAML,
+			opts:   []DumpOption{WithOrigins()},
+			expected: "" +
+				"# env URL\n" +
+				"url: https://example.com/it's\n" +
+				"# default\n" +
+				"tags:\n" +
+				"    - a\n" +
+				"    - b\n" +
+				"# default\n" +
+				"Timeout: 5s\n" +
+				"# env API_KEY\n" +
+				"apiKey: '******'\n" +
+				"# arg --debug\n" +
+				"Debug: true\n",
+		},
+		{
+			name:   "JSON",
+			format: FormatJSON,
+			expected: "" +
+				"{\n" +
+				"  \"url\": \"https://example.com/it's\",\n" +
+				"  \"tags\": [\n" +
+				"    \"a\",\n" +
+				"    \"b\"\n" +
+				"  ],\n" +
+				"  \"Timeout\": \"5s\",\n" +
+				"  \"apiKey\": \"******\",\n" +
+				"  \"Debug\": true\n" +
+				"}\n",
+		},
+		{
+			name:   "JSON with origins",
+			format: FormatJSON,
+			opts:   []DumpOption{WithOrigins()},
+			expected: "" +
+				"{\n" +
+				"  \"url\": {\n" +
+				"    \"value\": \"https://example.com/it's\",\n" +
+				"    \"source\": \"env URL\"\n" +
+				"  },\n" +
+				"  \"tags\": {\n" +
+				"    \"value\": [\n" +
+				"      \"a\",\n" +
+				"      \"b\"\n" +
+				"    ],\n" +
+				"    \"source\": \"default\"\n" +
+				"  },\n" +
+				"  \"Timeout\": {\n" +
+				"    \"value\": \"5s\",\n" +
+				"    \"source\": \"default\"\n" +
+				"  },\n" +
+				"  \"apiKey\": {\n" +
+				"    \"value\": \"******\",\n" +
+				"    \"source\": \"env API_KEY\"\n" +
+				"  },\n" +
+				"  \"Debug\": {\n" +
+				"    \"value\": true,\n" +
+				"    \"source\": \"arg --debug\"\n" +
+				"  }\n" +
+				"}\n",
+		},
+		{
+			name:   "Shell",
+			format: FormatShell,
+			expected: "" +
+				"export URL='https://example.com/it'\\''s'\n" +
+				"export TAGS=a,b\n" +
+				"export TIMEOUT=5s\n" +
+				"export API_KEY='******'\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.NoError(t, Dump(&cfg, &buf, tt.format, tt.opts...))
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+
+	assert.ErrorIs(t, Dump(&cfg, &bytes.Buffer{}, "toml"), ErrUnsupportedFormat)
+}
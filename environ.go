@@ -0,0 +1,77 @@
+package mageconfig
+
+import (
+	"reflect"
+	"sort"
+)
+
+// EnvOption configures how EnvMap and Environ export the configuration.
+type EnvOption func(*envOptions)
+
+// envOptions holds the settings of a single EnvMap or Environ call.
+type envOptions struct {
+	escapeRefs bool
+}
+
+// WithEscapedReferences makes EnvMap escape each "$" as "$$", for child processes that read the
+// variables with this package, so that the values are not expanded again. Other programs receive
+// the escaped values as they are.
+func WithEscapedReferences() EnvOption {
+	return func(o *envOptions) {
+		o.escapeRefs = true
+	}
+}
+
+// EnvMap returns the effective value of each field of the configuration with an 'env' tag, by the name
+// of its environment variable, formatted as the field would be read from the variable. It allows passing
+// the resolved configuration to child processes, e.g. with sh.RunWith. Fields with empty values, such as
+// empty strings and slices, and fields whose 'sources' tag excludes environment variables are omitted.
+// Secret values are included as they are.
+func EnvMap(cfg Config, opts ...EnvOption) map[string]string {
+	cfgValue := reflect.ValueOf(cfg)
+	if cfgValue.Kind() != reflect.Pointer || cfgValue.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	eo := &envOptions{}
+	for _, opt := range opts {
+		opt(eo)
+	}
+
+	env := make(map[string]string)
+	_ = setFields(Snapshot(cfg), func(field reflect.StructField, value reflect.Value) error {
+		envName := field.Tag.Get(tagEnv)
+		if envName == "" || !acceptsSource(field, SourceEnv) {
+			return nil
+		}
+		strVal := formatFieldValue(value)
+		if strVal == "" {
+			return nil
+		}
+		if eo.escapeRefs {
+			strVal = escapeReferences(strVal)
+		}
+		env[envName] = strVal
+		return nil
+	})
+
+	return env
+}
+
+// Environ returns the variables of EnvMap as "NAME=value" strings sorted by name, in the form of
+// os.Environ. They can be appended to the environment of a child process, e.g. to the Env field of
+// exec.Cmd.
+func Environ(cfg Config, opts ...EnvOption) []string {
+	env := EnvMap(cfg, opts...)
+	if env == nil {
+		return nil
+	}
+
+	environ := make([]string, 0, len(env))
+	for name, value := range env {
+		environ = append(environ, name+"="+value)
+	}
+	sort.Strings(environ)
+
+	return environ
+}
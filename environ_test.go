@@ -0,0 +1,76 @@
+package mageconfig
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type environTestConfig struct {
+	URL     string                   `env:"URL"`
+	Retries int                      `env:"RETRIES"`
+	Ports   []int                    `env:"PORTS"`
+	Limits  map[string]time.Duration `env:"LIMITS"`
+	Start   time.Time                `env:"START"`
+	Empty   string                   `env:"EMPTY"`
+	APIKey  string                   `env:"API_KEY" secret:"true"`
+	Region  string                   `env:"REGION" sources:"file"`
+	Debug   bool                     `arg:"debug"`
+}
+
+func TestEnvMap(t *testing.T) {
+	cfg := environTestConfig{
+		URL:    "https://example.com",
+		Ports:  []int{80, 443},
+		Limits: map[string]time.Duration{"read": time.Second, "write": 2 * time.Minute},
+		Start:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		APIKey: "abc$123",
+		Region: "eu",
+		Debug:  true,
+	}
+
+	assert.Equal(t, map[string]string{
+		"URL":     "https://example.com",
+		"RETRIES": "0",
+		"PORTS":   "80,443",
+		"LIMITS":  "read:1s,write:2m0s",
+		"START":   "2024-01-02T03:04:05Z",
+		"API_KEY": "abc$123",
+	}, EnvMap(&cfg))
+
+	assert.Equal(t, []string{
+		"API_KEY=abc$123",
+		"LIMITS=read:1s,write:2m0s",
+		"PORTS=80,443",
+		"RETRIES=0",
+		"START=2024-01-02T03:04:05Z",
+		"URL=https://example.com",
+	}, Environ(&cfg))
+
+	assert.Equal(t, "abc$$123", EnvMap(&cfg, WithEscapedReferences())["API_KEY"])
+	assert.Nil(t, EnvMap(cfg))
+	assert.Nil(t, Environ(cfg))
+}
+
+func TestEnvMapRoundTrip(t *testing.T) {
+	cfg := environTestConfig{
+		URL:    "https://example.com/a${HOME}b",
+		Ports:  []int{80, 443},
+		Limits: map[string]time.Duration{"read": time.Second},
+		Start:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		APIKey: "abc$123$",
+	}
+
+	// Beware: this modifies global state and is not safe for parallel test execution.
+	os.Args = []string{"cmd"}
+	defer func() { isLoaded = false }()
+	for name, value := range EnvMap(&cfg, WithEscapedReferences()) {
+		t.Setenv(name, value)
+	}
+
+	loaded := environTestConfig{}
+	assert.NoError(t, Load(&loaded, ""))
+	assert.Equal(t, cfg, loaded)
+}
@@ -12,13 +12,13 @@ import (
 
 // Config represents a set of configuration parameters.
 // Each field in the struct corresponds to a configuration parameter and may have tags such as 'file', 'env', 'arg', 'required',
-// 'default', 'desc', and 'depends'. These tags dictate the source of the parameter, its necessity, default value,
-// description, and any dependencies it might have on other parameters.
+// 'default', 'desc', 'depends', and 'secret'. These tags dictate the source of the parameter, its necessity, default value,
+// description, any dependencies it might have on other parameters, and whether its value must be hidden.
 type Config struct {
 	DatabaseURL       string `file:"dbURL" env:"DB_URL" arg:"db-url" required:"true" desc:"Database URL"`
 	BackupDatabaseURL string `file:"backupDBURL" env:"BACKUP_DB_URL" arg:"backup-db-url" desc:"Backup Database URL" depends:"DatabaseURL"`
 
-	APIKey     string        `file:"apiKey" env:"API_KEY" arg:"api-key" required:"true" desc:"API Key"`
+	APIKey     string        `file:"apiKey" env:"API_KEY" arg:"api-key" required:"true" secret:"true" desc:"API Key"`
 	MaxRetries int           `file:"maxRetries" env:"MAX_RETRIES" arg:"max-retries" default:"3" desc:"Maximum number of retries"`
 	Timeout    time.Duration `file:"timeout" env:"TIMEOUT" arg:"timeout" default:"5s" desc:"Timeout duration"`
 }
@@ -42,11 +42,32 @@ func init() {
 
 var Default = ShowConfig
 
-// ShowConfig displays the loaded configuration parameters.
-func ShowConfig() {
-	fmt.Println("Database URL:", appConfig.DatabaseURL)
-	fmt.Println("Backup Database URL:", appConfig.BackupDatabaseURL)
-	fmt.Println("API Key:", appConfig.APIKey)
-	fmt.Println("Max Retries:", appConfig.MaxRetries)
-	fmt.Println("Timeout:", appConfig.Timeout)
+// ShowConfig displays the loaded configuration parameters, with secret values redacted.
+func ShowConfig() error {
+	return mageconfig.Dump(appConfig, os.Stdout, mageconfig.FormatTable)
+}
+
+// ExplainConfig displays the effective configuration parameters and where each value came from.
+func ExplainConfig() error {
+	return mageconfig.Explain(os.Stdout, appConfig)
+}
+
+// ConfigSet saves the value of a configuration parameter, by its configuration file key, into
+// mage.config, keeping the other parameters and comments of the file, e.g. "mage configSet maxRetries 5".
+func ConfigSet(key, value string) error {
+	cfg := &Config{}
+	if err := mageconfig.SetValue(cfg, key, value); err != nil {
+		return err
+	}
+	return mageconfig.Save(cfg, "mage.config", mageconfig.FormatNative, mageconfig.WithKeys(key), mageconfig.WithSecrets())
+}
+
+// SampleConfig displays a commented sample configuration file, documenting all parameters.
+func SampleConfig() error {
+	sample, err := mageconfig.GenerateSample(&Config{}, mageconfig.FormatNative)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(sample)
+	return err
 }
@@ -0,0 +1,150 @@
+package mageconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrUnsupportedFormat is the error returned when a configuration cannot be written in the requested format.
+var ErrUnsupportedFormat = errors.New("unsupported format")
+
+// Format identifies a format in which a configuration is written.
+type Format string
+
+// Formats of configurations.
+const (
+	FormatNative Format = "native" // The "key: value" format of the configuration file.
+	FormatYAML   Format = "yaml"   // YAML, with a mapping of the configuration file keys.
+	FormatJSON   Format = "json"   // JSON, with an object of the configuration file keys.
+	FormatDotenv Format = "dotenv" // Dotenv, with "VAR=value" lines of the environment variables.
+	FormatTable  Format = "table"  // An aligned table of the field names and values.
+	FormatShell  Format = "shell"  // Shell, with "export VAR=value" lines of the environment variables.
+)
+
+// keyValue is the value of a configuration field, with the key it is written under, and an optional
+// comment written before it.
+type keyValue struct {
+	key     string
+	value   reflect.Value
+	comment string
+}
+
+// encodeNative writes the values in the native format of the configuration file, one per line.
+func encodeNative(values []keyValue) []byte {
+	var b bytes.Buffer
+	for _, kv := range values {
+		writeComment(&b, kv.comment)
+		b.WriteString(nativeLine(kv.key, formatFieldValue(kv.value)))
+		b.WriteByte('\n')
+	}
+
+	return b.Bytes()
+}
+
+// encodeShell writes the values as "export VAR=value" lines, quoted for POSIX shells.
+func encodeShell(values []keyValue) []byte {
+	var b bytes.Buffer
+	for _, kv := range values {
+		writeComment(&b, kv.comment)
+		b.WriteString("export " + kv.key + "=" + shellQuote(formatFieldValue(kv.value)))
+		b.WriteByte('\n')
+	}
+
+	return b.Bytes()
+}
+
+// writeComment writes the comment as a line starting with "#", unless it is empty.
+func writeComment(b *bytes.Buffer, comment string) {
+	if comment != "" {
+		b.WriteString("# " + comment + "\n")
+	}
+}
+
+// nativeLine returns the line of the native format for the key and value. The value is quoted if the
+// quotes would otherwise be stripped, or its leading or trailing whitespace trimmed, when read back.
+func nativeLine(key, value string) string {
+	if value == "" {
+		return key + kvSeparator
+	}
+	if value != strings.TrimSpace(value) || strings.HasPrefix(value, `"`) || strings.HasPrefix(value, "'") {
+		value = `"` + value + `"`
+	}
+
+	return key + kvSeparator + " " + value
+}
+
+// dotenvLine returns the line of the dotenv format for the variable and value. The value is quoted
+// if it contains whitespace, quotes, or characters interpreted by dotenv parsers.
+func dotenvLine(name, value string) string {
+	if strings.ContainsAny(value, " \t\n\"'`$#\\") {
+		value = strconv.Quote(value)
+	}
+
+	return name + "=" + value
+}
+
+// shellQuote quotes the value with single quotes for POSIX shells, unless it only contains characters
+// that need no quoting.
+func shellQuote(value string) string {
+	safe := value != ""
+	for _, r := range value {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("@%+=:,./_-", r)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return value
+	}
+
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// encodeJSON writes the values as a JSON object, keeping their order.
+func encodeJSON(values []keyValue) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("{\n")
+	for i, kv := range values {
+		key, err := json.Marshal(kv.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.MarshalIndent(fieldData(kv.value), "  ", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("encode %s: %w", kv.key, err)
+		}
+		fmt.Fprintf(&b, "  %s: %s", key, value)
+		if i < len(values)-1 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('\n')
+	}
+	b.WriteString("}\n")
+
+	return b.Bytes(), nil
+}
+
+// encodeYAML writes the values as a YAML mapping, keeping their order.
+func encodeYAML(values []keyValue) ([]byte, error) {
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	for _, kv := range values {
+		value := &yaml.Node{}
+		if err := value.Encode(fieldData(kv.value)); err != nil {
+			return nil, fmt.Errorf("encode %s: %w", kv.key, err)
+		}
+		key := &yaml.Node{Kind: yaml.ScalarNode, Value: kv.key, HeadComment: kv.comment}
+		doc.Content = append(doc.Content, key, value)
+	}
+	if len(doc.Content) == 0 {
+		return []byte("{}\n"), nil
+	}
+
+	return yaml.Marshal(doc)
+}
@@ -0,0 +1,309 @@
+package mageconfig
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const tagGit = "git" // Defines the key of the parameter in the git configuration, such as "mage.registry".
+
+// gitValue is a value of the git configuration together with its location.
+type gitValue struct {
+	value    string
+	location string
+}
+
+// gitSource provides configuration parameters from the git configuration, by the 'git' tag.
+// The system, global and repository configuration files are parsed directly, without running git,
+// and only once a field with the 'git' tag is looked up.
+type gitSource struct {
+	files  []string
+	values map[string]gitValue
+}
+
+// newGitSource returns a source reading the system, global and repository git configuration files.
+func newGitSource() *gitSource {
+	return &gitSource{files: gitConfigFiles()}
+}
+
+// Kind returns SourceGit.
+func (*gitSource) Kind() SourceKind {
+	return SourceGit
+}
+
+// Lookup returns the value of the git configuration key named by the 'git' tag of the field.
+func (s *gitSource) Lookup(field Field) (Value, bool, error) {
+	key := field.Tag.Get(tagGit)
+	if key == "" {
+		return Value{}, false, nil
+	}
+
+	if s.values == nil {
+		if err := s.readFiles(); err != nil {
+			return Value{}, false, err
+		}
+	}
+
+	v, ok := s.values[normalizeGitKey(key)]
+	if !ok {
+		return Value{}, false, nil
+	}
+
+	return Value{Raw: v.value, Location: v.location}, true, nil
+}
+
+// readFiles reads the git configuration files in order, so that the repository values win over
+// the global ones, which win over the system ones. Missing files are ignored.
+func (s *gitSource) readFiles() error {
+	s.values = make(map[string]gitValue)
+	for _, file := range s.files {
+		if err := s.readFile(file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// gitConfigFiles returns the paths of the system, global and repository git configuration files.
+// The GIT_CONFIG_SYSTEM, GIT_CONFIG_NOSYSTEM and GIT_CONFIG_GLOBAL variables are respected as by git.
+func gitConfigFiles() []string {
+	var files []string
+
+	if os.Getenv("GIT_CONFIG_NOSYSTEM") == "" {
+		if system, ok := os.LookupEnv("GIT_CONFIG_SYSTEM"); ok {
+			files = append(files, system)
+		} else {
+			files = append(files, "/etc/gitconfig")
+		}
+	}
+
+	if global, ok := os.LookupEnv("GIT_CONFIG_GLOBAL"); ok {
+		files = append(files, global)
+	} else {
+		xdgConfigHome := os.Getenv("XDG_CONFIG_HOME")
+		home, err := os.UserHomeDir()
+		if xdgConfigHome == "" && err == nil {
+			xdgConfigHome = filepath.Join(home, ".config")
+		}
+		if xdgConfigHome != "" {
+			files = append(files, filepath.Join(xdgConfigHome, "git", "config"))
+		}
+		if err == nil {
+			files = append(files, filepath.Join(home, ".gitconfig"))
+		}
+	}
+
+	if gitDir := findGitDir(); gitDir != "" {
+		files = append(files, filepath.Join(gitDir, "config"))
+	}
+
+	return files
+}
+
+// findGitDir returns the git directory of the repository containing the current working directory,
+// or an empty string if there is none. Worktrees and submodules, whose ".git" is a file pointing to
+// the actual git directory, are supported.
+func findGitDir() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+
+	for {
+		dotGit := filepath.Join(dir, ".git")
+		if info, err := os.Stat(dotGit); err == nil {
+			if info.IsDir() {
+				return dotGit
+			}
+			return readGitDirFile(dotGit)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// readGitDirFile returns the git directory a ".git" file points to with its "gitdir: path" line.
+// For worktrees, the configuration lives in the common git directory.
+func readGitDirFile(dotGit string) string {
+	content, err := os.ReadFile(dotGit)
+	if err != nil {
+		return ""
+	}
+
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir:")
+	if !ok {
+		return ""
+	}
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(dotGit), gitDir)
+	}
+
+	if commonDir, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		common := strings.TrimSpace(string(commonDir))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(gitDir, common)
+		}
+		return common
+	}
+
+	return gitDir
+}
+
+// readFile parses a git configuration file in the INI format and adds its values to the source.
+// Values of keys defined several times are overwritten by the last definition.
+func (s *gitSource) readFile(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		startLine := lineNum
+
+		// A backslash at the end of the line continues the value on the next line.
+		for strings.HasSuffix(line, `\`) && !strings.HasSuffix(line, `\\`) && scanner.Scan() {
+			line = line[:len(line)-1] + scanner.Text()
+			lineNum++
+		}
+
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		// Section header, optionally followed by a variable on the same line.
+		if line[0] == '[' {
+			end := strings.IndexByte(line, ']')
+			if end < 0 {
+				return fmt.Errorf("%s:%d: invalid section header", file, startLine)
+			}
+			if section, err = parseGitSection(line[1:end]); err != nil {
+				return fmt.Errorf("%s:%d: %w", file, startLine, err)
+			}
+			if line = strings.TrimSpace(line[end+1:]); line == "" || line[0] == '#' || line[0] == ';' {
+				continue
+			}
+		}
+
+		if section == "" {
+			return fmt.Errorf("%s:%d: variable outside of a section", file, startLine)
+		}
+
+		name, value, hasValue := strings.Cut(line, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !hasValue {
+			value = "true" // A variable without a value is a boolean true.
+		} else if value, err = parseGitValue(value); err != nil {
+			return fmt.Errorf("%s:%d: %w", file, startLine, err)
+		}
+
+		s.values[section+"."+name] = gitValue{value: value, location: fmt.Sprintf("%s:%d", file, startLine)}
+	}
+
+	return scanner.Err()
+}
+
+// parseGitSection returns the normalized name of a section from its header without brackets:
+// `section "subsection"` becomes "section.subsection", with only the section name lower-cased.
+func parseGitSection(header string) (string, error) {
+	name, subsection, hasSubsection := strings.Cut(header, " ")
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", errors.New("empty section name")
+	}
+	if !hasSubsection {
+		return name, nil // The deprecated [section.subsection] syntax is lower-cased entirely.
+	}
+
+	subsection = strings.TrimSpace(subsection)
+	if len(subsection) < 2 || subsection[0] != '"' || subsection[len(subsection)-1] != '"' {
+		return "", fmt.Errorf("invalid subsection: %s", subsection)
+	}
+	subsection = strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(subsection[1 : len(subsection)-1])
+
+	return name + "." + subsection, nil
+}
+
+// parseGitValue unquotes a git configuration value, removing inline comments and processing escapes.
+func parseGitValue(raw string) (string, error) {
+	var b strings.Builder
+	inQuotes := false
+	pendingSpace := ""
+	raw = strings.TrimSpace(raw)
+
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == '\\':
+			if i+1 >= len(raw) {
+				return "", errors.New("invalid escape at the end of the value")
+			}
+			i++
+			switch raw[i] {
+			case 'n':
+				c = '\n'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case '"', '\\':
+				c = raw[i]
+			default:
+				return "", fmt.Errorf("invalid escape: \\%c", raw[i])
+			}
+			b.WriteString(pendingSpace)
+			pendingSpace = ""
+			b.WriteByte(c)
+			continue
+		case !inQuotes && (c == '#' || c == ';'):
+			return b.String(), nil // The rest of the line is a comment.
+		case !inQuotes && (c == ' ' || c == '\t'):
+			// Spaces are kept only if followed by other characters of the value.
+			pendingSpace += string(c)
+			continue
+		default:
+			b.WriteString(pendingSpace)
+			pendingSpace = ""
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteString(pendingSpace)
+		pendingSpace = ""
+	}
+
+	if inQuotes {
+		return "", errors.New("unterminated quote")
+	}
+
+	return b.String(), nil
+}
+
+// normalizeGitKey lower-cases the section and variable names of a key such as "Mage.Registry",
+// keeping the case of the subsection, as git does.
+func normalizeGitKey(key string) string {
+	first := strings.IndexByte(key, '.')
+	last := strings.LastIndexByte(key, '.')
+	if first < 0 {
+		return strings.ToLower(key)
+	}
+
+	return strings.ToLower(key[:first]) + key[first:last] + "." + strings.ToLower(key[last+1:])
+}
@@ -0,0 +1,92 @@
+package mageconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGitValue(t *testing.T) {
+	testCases := []struct {
+		raw     string
+		want    string
+		wantErr string
+	}{
+		{raw: "value", want: "value"},
+		{raw: "  spaced   value  ", want: "spaced   value"},
+		{raw: "value # comment", want: "value"},
+		{raw: "value ; comment", want: "value"},
+		{raw: `"quoted # value "`, want: "quoted # value "},
+		{raw: `a"b c"d`, want: "ab cd"},
+		{raw: `tab\tnewline\n\"quote\" back\\slash`, want: "tab\tnewline\n\"quote\" back\\slash"},
+		{raw: `"unterminated`, wantErr: "unterminated quote"},
+		{raw: `invalid \q`, wantErr: `invalid escape: \q`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.raw, func(t *testing.T) {
+			got, err := parseGitValue(tc.raw)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestNormalizeGitKey(t *testing.T) {
+	assert.Equal(t, "mage.registry", normalizeGitKey("Mage.Registry"))
+	assert.Equal(t, "remote.Origin.url", normalizeGitKey("Remote.Origin.URL"))
+	assert.Equal(t, "core", normalizeGitKey("Core"))
+}
+
+func TestLoadFromGit(t *testing.T) {
+	type testConfig struct {
+		Registry string        `git:"mage.registry"`
+		Timeout  time.Duration `git:"mage.timeout"`
+		Verbose  bool          `git:"mage.verbose"`
+		User     string        `git:"user.name" env:"TEST_GIT_USER"`
+		URL      string        `git:"remote.Origin.url"`
+		Fetch    string        `git:"remote.Origin.fetch"`
+	}
+
+	// Create a repository whose configuration overrides the global one, and run from its subdirectory.
+	repo := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(repo, ".git"), 0o755))
+	assert.NoError(t, os.MkdirAll(filepath.Join(repo, "sub"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(repo, ".git", "config"), []byte("[mage]\n\tregistry = ghcr.io\n"), 0o600))
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	global, err := filepath.Abs("testdata/gitconfig")
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(filepath.Join(repo, "sub")))
+	defer func() { assert.NoError(t, os.Chdir(wd)) }()
+
+	// Beware: this modifies global state and is not safe for parallel test execution.
+	os.Args = []string{"cmd"}
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_CONFIG_GLOBAL", global)
+	t.Setenv("TEST_GIT_USER", "env user")
+	defer func() { isLoaded = false }()
+
+	cfg := testConfig{}
+	assert.NoError(t, Load(&cfg, ""))
+	assert.Equal(t, testConfig{
+		Registry: "ghcr.io",
+		Timeout:  30 * time.Second,
+		Verbose:  true,
+		User:     "env user",
+		URL:      "https://example.com/repo.git",
+		Fetch:    "a\tb c",
+	}, cfg)
+
+	origins := Origins(&cfg)
+	assert.Equal(t, Origin{Source: SourceGit, Location: filepath.Join(repo, ".git", "config") + ":2"}, origins["Registry"])
+	assert.Equal(t, Origin{Source: SourceGit, Location: global + ":6"}, origins["Timeout"])
+	assert.Equal(t, Origin{Source: SourceEnv, Location: "TEST_GIT_USER"}, origins["User"])
+}
@@ -0,0 +1,169 @@
+package mageconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Syntax of the references expanded in configuration values.
+const (
+	refStart       = "${"     // The start of a reference.
+	refEnd         = "}"      // The end of a reference.
+	refEscape      = "$$"     // The escape sequence for a literal "$".
+	refField       = "field:" // The prefix of a reference to another configuration field.
+	refFallbackSep = ":-"     // The separator between an environment variable and its fallback value.
+)
+
+// ErrInterpolation is the error returned when references in a configuration value cannot be expanded.
+var ErrInterpolation = errors.New("interpolate value")
+
+// rawValue is the string value of a configuration field, as read from its source.
+type rawValue struct {
+	value  string
+	expand bool   // Whether the value contains references to expand once all sources are loaded.
+	origin Origin // Where the value came from.
+}
+
+// setRawValue records the raw value of a field read from a source and marks the field as set.
+// Values that contain references are only recorded; they are expanded and assigned by expandValues
+// once all sources are loaded. Other values, and values of command-line arguments, are assigned at once.
+func setRawValue(field reflect.StructField, value reflect.Value, strVal string, origin Origin,
+	isSet map[string]*bool, raw map[string]*rawValue,
+) error {
+	expand := origin.Source != SourceArg && strings.Contains(strVal, "$")
+	if !expand {
+		if err := setFieldValue(field, value, strVal); err != nil {
+			return err
+		}
+	}
+	raw[field.Name] = &rawValue{value: strVal, expand: expand, origin: origin}
+	*isSet[field.Name] = true
+
+	return nil
+}
+
+// expandValues expands the references in the recorded raw values and assigns them to their fields.
+// The supported references are "${ENV_VAR}", "${ENV_VAR:-fallback}" and "${field:OtherField}",
+// and "$$" stands for a literal "$". References to fields see their final values.
+func expandValues(cfg Config, raw map[string]*rawValue) error {
+	e := &expander{cfgType: reflect.TypeOf(cfg).Elem(), raw: raw, expanding: make(map[string]bool)}
+
+	return setFields(cfg, func(field reflect.StructField, value reflect.Value) error {
+		rv, ok := raw[field.Name]
+		if !ok || !rv.expand {
+			return nil
+		}
+
+		expanded, err := e.field(field.Name)
+		if err != nil {
+			return err
+		}
+
+		return setFieldValue(field, value, expanded)
+	})
+}
+
+// escapeReferences escapes each "$" of the value as "$$", so that it is not expanded when read back.
+func escapeReferences(s string) string {
+	return strings.ReplaceAll(s, "$", refEscape)
+}
+
+// expander expands references in raw values, detecting reference cycles between fields.
+type expander struct {
+	cfgType   reflect.Type
+	raw       map[string]*rawValue
+	expanding map[string]bool
+}
+
+// field returns the expanded raw value of the field with the given name.
+// Fields without a value expand to an empty string.
+func (e *expander) field(name string) (string, error) {
+	if _, err := lookupLoadableField(e.cfgType, name); err != nil {
+		return "", err
+	}
+
+	rv, ok := e.raw[name]
+	if !ok {
+		return "", nil
+	}
+	if !rv.expand {
+		return rv.value, nil
+	}
+
+	if e.expanding[name] {
+		return "", fmt.Errorf("%w: field %s: reference cycle", ErrInterpolation, name)
+	}
+	e.expanding[name] = true
+	defer delete(e.expanding, name)
+
+	expanded, err := expandString(rv.value, e.field)
+	if errors.Is(err, ErrInterpolation) { // Already wrapped by a referenced field.
+		return "", err
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: field %s: %w", ErrInterpolation, name, err)
+	}
+
+	// Cache the expanded value, so that it is not expanded again when referenced from other fields.
+	rv.value, rv.expand = expanded, false
+
+	return expanded, nil
+}
+
+// expandString expands the references in s, using lookupField to resolve references to other fields.
+func expandString(s string, lookupField func(name string) (string, error)) (string, error) {
+	var b strings.Builder
+	for {
+		i := strings.IndexByte(s, '$')
+		if i < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		b.WriteString(s[:i])
+		s = s[i:]
+
+		switch {
+		case strings.HasPrefix(s, refEscape):
+			b.WriteByte('$')
+			s = s[len(refEscape):]
+
+		case strings.HasPrefix(s, refStart):
+			end := strings.Index(s, refEnd)
+			if end < 0 {
+				return "", errors.New("unterminated reference")
+			}
+			ref := s[len(refStart):end]
+			s = s[end+len(refEnd):]
+
+			value, err := expandReference(ref, lookupField)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(value)
+
+		default: // A lone "$" is kept as is.
+			b.WriteByte('$')
+			s = s[1:]
+		}
+	}
+}
+
+// expandReference returns the value of a single reference, without the surrounding "${" and "}".
+func expandReference(ref string, lookupField func(name string) (string, error)) (string, error) {
+	if name, ok := strings.CutPrefix(ref, refField); ok {
+		return lookupField(name)
+	}
+
+	name, fallback, hasFallback := strings.Cut(ref, refFallbackSep)
+	if name == "" {
+		return "", errors.New("empty reference")
+	}
+	if value, ok := os.LookupEnv(name); ok && (value != "" || !hasFallback) {
+		return value, nil
+	}
+
+	return fallback, nil
+}
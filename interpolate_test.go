@@ -0,0 +1,134 @@
+package mageconfig
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandString(t *testing.T) {
+	t.Setenv("TEST_HOST", "example.com")
+	t.Setenv("TEST_EMPTY", "")
+
+	fields := map[string]string{"Port": "8080"}
+	lookupField := func(name string) (string, error) {
+		return fields[name], nil
+	}
+
+	testCases := []struct {
+		name    string
+		s       string
+		want    string
+		wantErr string
+	}{
+		{name: "no references", s: "plain", want: "plain"},
+		{name: "environment variable", s: "https://${TEST_HOST}", want: "https://example.com"},
+		{name: "unset environment variable", s: "${TEST_UNSET}", want: ""},
+		{name: "fallback for unset variable", s: "${TEST_UNSET:-localhost}", want: "localhost"},
+		{name: "fallback for empty variable", s: "${TEST_EMPTY:-localhost}", want: "localhost"},
+		{name: "fallback not used", s: "${TEST_HOST:-localhost}", want: "example.com"},
+		{name: "field reference", s: "${TEST_HOST}:${field:Port}", want: "example.com:8080"},
+		{name: "escaped dollar", s: "pa$$word $${TEST_HOST}", want: "pa$word ${TEST_HOST}"},
+		{name: "lone dollar", s: "$5 and $", want: "$5 and $"},
+		{name: "unterminated reference", s: "${TEST_HOST", wantErr: "unterminated reference"},
+		{name: "empty reference", s: "${}", wantErr: "empty reference"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := expandString(tc.s, lookupField)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestLoadInterpolation(t *testing.T) {
+	type testConfig struct {
+		Host    string `arg:"host" default:"localhost"`
+		Port    int    `arg:"port" env:"TEST_PORT" default:"${TEST_DEFAULT_PORT:-8080}"`
+		URL     string `env:"TEST_URL" default:"https://${field:Host}:${field:Port}"`
+		Escaped string `default:"$${field:Host}"`
+		APIKey  string `env:"TEST_API_KEY" secret:"true"`
+	}
+
+	testCases := []struct {
+		name       string
+		env        map[string]string
+		args       []string
+		wantConfig testConfig
+		wantErr    string
+	}{
+		{
+			name: "defaults",
+			wantConfig: testConfig{
+				Host:    "localhost",
+				Port:    8080,
+				URL:     "https://localhost:8080",
+				Escaped: "${field:Host}",
+			},
+		},
+		{
+			name: "references see final values",
+			env:  map[string]string{"TEST_DEFAULT_PORT": "9090"},
+			args: []string{"-host=example.com"},
+			wantConfig: testConfig{
+				Host:    "example.com",
+				Port:    9090,
+				URL:     "https://example.com:9090",
+				Escaped: "${field:Host}",
+			},
+		},
+		{
+			name: "environment values are expanded",
+			env:  map[string]string{"TEST_URL": "http://${field:Host}"},
+			wantConfig: testConfig{
+				Host:    "localhost",
+				Port:    8080,
+				URL:     "http://localhost",
+				Escaped: "${field:Host}",
+			},
+		},
+		{
+			name:    "reference cycle",
+			env:     map[string]string{"TEST_URL": "${field:Port}", "TEST_PORT": "${field:URL}"},
+			wantErr: "interpolate value: field Port: reference cycle",
+		},
+		{
+			name:    "unknown field",
+			env:     map[string]string{"TEST_URL": "${field:Hots}"},
+			wantErr: "interpolate value: field URL: unknown field \"Hots\"",
+		},
+		{
+			name:    "values are not included in errors",
+			env:     map[string]string{"TEST_API_KEY": "hunter2${oops"},
+			wantErr: "interpolate value: field APIKey: unterminated reference",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Beware: this modifies global state and is not safe for parallel test execution.
+			os.Args = append([]string{"cmd"}, tc.args...)
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			defer func() { isLoaded = false }()
+
+			cfg := testConfig{}
+			err := Load(&cfg, "")
+			if tc.wantErr != "" {
+				assert.ErrorIs(t, err, ErrInterpolation)
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantConfig, cfg)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package mageconfig
 import (
 	"bufio"
 	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"reflect"
@@ -12,22 +13,35 @@ import (
 
 // Tag constants used for struct field tags.
 const (
-	tagArg         = "arg"      // Defines the name of the command-line argument.
-	tagEnv         = "env"      // Defines the name of the environment variable.
-	tagFile        = "file"     // Defines the name of the parameter in the configuration file.
-	tagDefault     = "default"  // Defines the default value of the parameter.
-	tagDesc        = "desc"     // Provides a description for the parameter.
-	tagDepends     = "depends"  // Specifies other parameters that this parameter depends on.
-	tagRequired    = "required" // Specifies whether the parameter is required.
-	argPrefix      = "-"        // The prefix used for command-line arguments.
-	sliceSeparator = ","        // The separator used for slice elements.
-	kvSeparator    = ":"        // The separator used for key-value pairs in the configuration file.
+	tagArg           = "arg"            // Defines the name of the command-line argument.
+	tagEnv           = "env"            // Defines the name of the environment variable.
+	tagFile          = "file"           // Defines the name of the parameter in the configuration file.
+	tagDefault       = "default"        // Defines the default value of the parameter.
+	tagDesc          = "desc"           // Provides a description for the parameter.
+	tagDepends       = "depends"        // Specifies other parameters that this parameter depends on.
+	tagRequired      = "required"       // Specifies whether the parameter is required.
+	tagConflicts     = "conflicts"      // Specifies other parameters that must not be set together with this parameter.
+	tagOneOfRequired = "oneof_required" // Specifies a group of parameters of which at least one must be set.
+	tagRequiredIf    = "requiredif"     // Specifies a condition on another parameter under which this parameter is required.
+	tagRequiredWith  = "requiredwith"   // Specifies other parameters that require this parameter when any of them is set.
+	tagFileEnv       = "fileenv"        // Specifies whether the value can be read from a file named by the <ENV>_FILE variable.
+	tagEnum          = "enum"           // Specifies the comma-separated list of allowed values of the parameter.
+	tagMin           = "min"            // Specifies the minimum value of a numeric or duration parameter.
+	tagMax           = "max"            // Specifies the maximum value of a numeric or duration parameter.
+	argPrefix        = "-"              // The prefix used for command-line arguments.
+	fileEnvSuffix    = "_FILE"          // The suffix of the environment variable containing the path to a value file.
+	filePrefix       = "file://"        // The prefix of a command-line value that is read from a file.
+	fileShortPrefix  = "@"              // The short prefix of a command-line value that is read from a file.
+	sliceSeparator   = ","              // The separator used for slice elements.
+	kvSeparator      = ":"              // The separator used for key-value pairs in the configuration file.
 )
 
 // List of default Mage commads and options.
 var (
 	defaultMageCommands = []string{"-l", "-h"}
 	defaultMageOptions  = []string{"-h", "-t", "-v"}
+	// Mage options followed by a value, skipped when detecting the target.
+	mageValueOptions = []string{"-t", "-d", "-w", "-compile", "-gocmd", "-goos", "-goarch", "-ldflags"}
 )
 
 // Global variables to manage loading state and ensure thread safety during configuration load.
@@ -41,6 +55,14 @@ var (
 	ErrRequiredNotSet = errors.New("required parameter not set")
 	// ErrDependsNotSet is the error returned when a dependent field configuration value is not set.
 	ErrDependsNotSet = errors.New("dependent parameter not set")
+	// ErrConflictsSet is the error returned when mutually exclusive configuration values are set together.
+	ErrConflictsSet = errors.New("conflicting parameters set")
+	// ErrOneOfRequiredNotSet is the error returned when none of the configuration values of a group is set.
+	ErrOneOfRequiredNotSet = errors.New("one of required parameters not set")
+	// ErrRequiredIfNotSet is the error returned when a conditionally required configuration value is not set.
+	ErrRequiredIfNotSet = errors.New("conditionally required parameter not set")
+	// ErrRequiredWithNotSet is the error returned when a configuration value required with another one is not set.
+	ErrRequiredWithNotSet = errors.New("parameter required with another parameter not set")
 )
 
 // Config is an interface that all configuration structs should implement.
@@ -50,11 +72,21 @@ var (
 type Config interface{}
 
 // Load reads configuration parameters from a file, environment variables, and command-line arguments
-// into a configuration struct. It also checks if any required parameters are not set and returns an
-// error if any are missing.
-func Load(cfg Config, file string) error {
+// into a configuration struct. The order of precedence of the sources can be changed, and custom sources
+// can be added, with options. Before reading any source, the struct definition is verified with Check.
+// It also checks if any required parameters are not set and returns an error if any are missing,
+// and if any values are not allowed by their 'enum', 'min' and 'max' tags.
+// Finally, if the configuration struct implements the Validator interface, its Validate method is called.
+func Load(cfg Config, file string, opts ...Option) error {
+	o := newOptions(opts)
+
 	if isHelpRequested() {
-		printUsage(reflect.TypeOf(cfg).Elem())
+		helpOpts := newHelpOptions(o.help)
+		helpOpts.profiles = o.profiles
+		if err := printHelp(flag.CommandLine.Output(), cfg, helpOpts); err != nil {
+			fmt.Fprintln(flag.CommandLine.Output(), err)
+			os.Exit(1)
+		}
 		os.Exit(0)
 	}
 
@@ -82,29 +114,22 @@ func Load(cfg Config, file string) error {
 		return errors.New("config must be a pointer to a struct")
 	}
 
-	// Map to keep track of which configuration parameters have been set.
-	isSet := make(map[string]*bool)
-	initializeIsSet(cfg, isSet)
-
-	// Set the default values for configuration parameters.
-	if err := setDefault(cfg, isSet); err != nil {
+	// Check that the configuration struct is defined correctly before reading any source.
+	if err := Check(cfg); err != nil {
 		return err
 	}
 
-	// Load the configuration from a file.
-	if err := loadFromFile(cfg, file, isSet); err != nil {
-		return err
-	}
+	// Keep a copy of the configuration as passed, to reload it from scratch when watching for changes.
+	initial := copyConfig(cfg)
 
-	// Load the configuration from environment variables.
-	if err := loadFromEnv(cfg, isSet); err != nil {
+	isSet, report, err := readSources(cfg, file, o)
+	if err != nil {
 		return err
 	}
 
-	// Load the configuration from command-line arguments.
-	if err := loadFromArgs(cfg, isSet); err != nil {
-		return err
-	}
+	// Keep how the configuration was loaded for Origins, Explain and Watch.
+	report.initial = initial
+	storeReport(cfg, report)
 
 	// Ensure that the configuration is loaded only once.
 	once.Do(func() {
@@ -112,7 +137,45 @@ func Load(cfg Config, file string) error {
 	})
 
 	// Check that all required and dependent fields in the configuration have been set.
-	return checkRequiredAndDepends(cfg, isSet)
+	if err := checkRequiredAndDepends(cfg, isSet); err != nil {
+		return err
+	}
+
+	// Run the user-defined validation of the configuration struct and its nested structs.
+	return validate(cfg)
+}
+
+// readSources reads the configuration from the sources, from the lowest to the highest precedence,
+// expands the references in the values and computes the default values that depend on other fields.
+// It returns which configuration parameters have been set, and the report of the load.
+func readSources(cfg Config, file string, o *options) (map[string]*bool, *loadReport, error) {
+	sources, err := o.newSources(cfg, file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Map to keep track of which configuration parameters have been set.
+	isSet := make(map[string]*bool)
+	initializeIsSet(cfg, isSet)
+	// Map to keep the raw values of configuration parameters, as read from their sources.
+	raw := make(map[string]*rawValue)
+
+	// Load the configuration from the sources, from the lowest to the highest precedence.
+	for _, src := range sources {
+		if err := loadFromSource(cfg, src, isSet, raw); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Expand the references in the values, now that all sources are loaded.
+	if err := expandValues(cfg, raw); err != nil {
+		return nil, nil, err
+	}
+
+	// Compute the default values that depend on other fields.
+	applyDefaults(cfg, isSet, raw)
+
+	return isSet, newReport(file, o, raw, sources), nil
 }
 
 // DropArgsAfterTarget removes command-line arguments that come after the target argument (with the specified prefix).
@@ -135,6 +198,25 @@ func DropArgsAfterTarget() {
 	}
 }
 
+// detectTarget returns the name of the mage target being run, in lower case, detected as the first
+// command-line argument that is neither an option nor the value of a mage option. It returns an empty
+// string if no target is specified, e.g. when the default target is run.
+func detectTarget() string {
+	for i := 1; i < len(os.Args); i++ {
+		arg := os.Args[i]
+		if contains(mageValueOptions, arg) {
+			i++ // Skip the value of the option.
+			continue
+		}
+		if strings.HasPrefix(arg, argPrefix) {
+			continue
+		}
+		return strings.ToLower(arg)
+	}
+
+	return ""
+}
+
 // contains check if a string slice contains a specific string.
 func contains(s []string, str string) bool {
 	for _, v := range s {
@@ -156,43 +238,112 @@ func initializeIsSet(cfg Config, isSet map[string]*bool) {
 	}
 }
 
-// setDefault sets default values for each field in a struct based on the 'tagDefault' tag.
-func setDefault(cfg Config, isSet map[string]*bool) error {
-	return setFields(cfg, func(field reflect.StructField, value reflect.Value) error {
-		defaultValue := field.Tag.Get(tagDefault)
-		if defaultValue == "" {
-			return nil
+// defaultSource provides the default values of configuration parameters from the 'default' tag.
+type defaultSource struct{}
+
+// Kind returns SourceDefault.
+func (defaultSource) Kind() SourceKind {
+	return SourceDefault
+}
+
+// Lookup returns the value of the 'default' tag of the field.
+func (defaultSource) Lookup(field Field) (Value, bool, error) {
+	defaultValue := field.Tag.Get(tagDefault)
+	if defaultValue == "" {
+		return Value{}, false, nil
+	}
+
+	return Value{Raw: defaultValue}, true, nil
+}
+
+// fileEntry is a value of the configuration file together with its location.
+type fileEntry struct {
+	value string
+	file  string
+	line  int
+}
+
+// fileSource provides configuration parameters from a configuration file, by the 'file' tag.
+type fileSource struct {
+	entries map[string]fileEntry
+	files   []string // The files actually read, in order.
+}
+
+// newFileSource reads the configuration file into a fileSource. The values are layered in the following
+// order, each layer overriding the previous ones: the global values of the file, its sections of the
+// active profiles (such as "[profile.prod]"), its section of the target (such as "[deploy]"), and then
+// the same layers of the profile files (such as "mage.prod.config"). Missing files provide no values.
+func newFileSource(file, target string, profiles []string) (*fileSource, error) {
+	src := &fileSource{entries: make(map[string]fileEntry)}
+
+	for _, f := range configFiles(file, profiles) {
+		sections, err := readConfigFile(f)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
 		}
+		src.files = append(src.files, f)
 
-		if err := setFieldByKind(field, value, defaultValue); err != nil {
-			return err
+		layers := []string{""}
+		for _, profile := range profiles {
+			layers = append(layers, profileSectionPrefix+profile)
+		}
+		if target != "" {
+			layers = append(layers, target)
 		}
-		*isSet[field.Name] = true
+		for _, layer := range layers {
+			for key, entry := range sections[strings.ToLower(layer)] {
+				src.entries[key] = entry
+			}
+		}
+	}
 
-		return nil
-	})
+	return src, nil
 }
 
-// loadFromFile loads configuration parameters from a file into a configuration struct.
-func loadFromFile(cfg Config, file string, isSet map[string]*bool) error {
+// configFiles returns the configuration file followed by the profile files of the active profiles, in
+// the order they are layered. It returns nil if there is no configuration file.
+func configFiles(file string, profiles []string) []string {
 	if file == "" {
 		return nil
 	}
 
+	files := []string{file}
+	for _, profile := range profiles {
+		files = append(files, profileFile(file, profile))
+	}
+
+	return files
+}
+
+// readConfigFile reads the configuration file in the native format, the only one supported for loading,
+// into a map of sections, each mapping keys to entries. Values before the first section header, such as
+// "[deploy]", belong to the global section "". Section names are lower-cased.
+func readConfigFile(file string) (map[string]map[string]fileEntry, error) {
 	f, err := os.Open(file)
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return nil
-		}
-		return err
+		return nil, err
 	}
 	defer f.Close()
 
-	// Read the file into a map.
-	fileContent := make(map[string]string)
+	// Read the file into a map, keeping the line numbers for provenance.
+	section := ""
+	sections := map[string]map[string]fileEntry{section: {}}
 	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
+	for lineNum := 1; scanner.Scan(); lineNum++ {
 		line := scanner.Text()
+
+		// A section header starts the values of a section.
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			section = strings.ToLower(strings.TrimSpace(trimmed[1 : len(trimmed)-1]))
+			if sections[section] == nil {
+				sections[section] = make(map[string]fileEntry)
+			}
+			continue
+		}
+
 		parts := strings.SplitN(line, kvSeparator, 2)
 		if len(parts) != 2 {
 			continue // Skip lines with invalid format.
@@ -207,114 +358,180 @@ func loadFromFile(cfg Config, file string, isSet map[string]*bool) error {
 			value = value[1 : len(value)-1]
 		}
 
-		fileContent[key] = value
+		sections[section][key] = fileEntry{value: value, file: file, line: lineNum}
 	}
 
 	if scanner.Err() != nil {
-		return scanner.Err()
+		return nil, scanner.Err()
 	}
 
-	// Load fields from the map.
-	return setFields(cfg, func(field reflect.StructField, value reflect.Value) error {
-		fileName := field.Tag.Get(tagFile)
-		if fileName == "" {
-			return nil
-		}
+	return sections, nil
+}
 
-		fileValue, ok := fileContent[fileName]
-		if !ok {
-			return nil
-		}
+// Kind returns SourceFile.
+func (*fileSource) Kind() SourceKind {
+	return SourceFile
+}
 
-		if err := setFieldByKind(field, value, fileValue); err != nil {
-			return err
-		}
-		*isSet[field.Name] = true
+// Lookup returns the value of the parameter named by the 'file' tag of the field.
+func (s *fileSource) Lookup(field Field) (Value, bool, error) {
+	fileName := field.Tag.Get(tagFile)
+	if fileName == "" {
+		return Value{}, false, nil
+	}
 
-		return nil
-	})
+	entry, ok := s.entries[fileName]
+	if !ok {
+		return Value{}, false, nil
+	}
+
+	return Value{Raw: entry.value, Location: fmt.Sprintf("%s:%d", entry.file, entry.line)}, true, nil
 }
 
-// loadFromEnv loads configuration parameters from environment variables into a configuration struct.
-func loadFromEnv(cfg Config, isSet map[string]*bool) error {
-	return setFields(cfg, func(field reflect.StructField, value reflect.Value) error {
-		envName := field.Tag.Get(tagEnv)
-		if envName == "" {
-			return nil
-		}
+// envSource provides configuration parameters from environment variables, by the 'env' tag.
+type envSource struct{}
 
-		envValue, ok := os.LookupEnv(envName)
-		if !ok {
-			return nil
-		}
+// Kind returns SourceEnv.
+func (envSource) Kind() SourceKind {
+	return SourceEnv
+}
 
-		if err := setFieldByKind(field, value, envValue); err != nil {
-			return err
-		}
-		*isSet[field.Name] = true
+// Lookup returns the value of the environment variable named by the 'env' tag of the field.
+// With the 'fileenv' tag, the value may also be read from the file named by the <ENV>_FILE variable.
+func (envSource) Lookup(field Field) (Value, bool, error) {
+	envName := field.Tag.Get(tagEnv)
+	if envName == "" {
+		return Value{}, false, nil
+	}
 
-		return nil
-	})
+	if envValue, ok := os.LookupEnv(envName); ok {
+		return Value{Raw: envValue, Location: envName}, true, nil
+	}
+
+	// The value may be read from a file named by the <ENV>_FILE variable, as done for Docker secrets.
+	if field.Tag.Get(tagFileEnv) != "true" {
+		return Value{}, false, nil
+	}
+	path, ok := os.LookupEnv(envName + fileEnvSuffix)
+	if !ok {
+		return Value{}, false, nil
+	}
+	envValue, err := readValueFile(path)
+	if err != nil {
+		return Value{}, false, fmt.Errorf("%s%s: %w", envName, fileEnvSuffix, err)
+	}
+
+	return Value{Raw: envValue, Location: envName + fileEnvSuffix}, true, nil
 }
 
-// loadFromArgs loads configuration parameters from command-line arguments into a configuration struct.
-func loadFromArgs(cfg Config, isSet map[string]*bool) error {
-	return setFields(cfg, func(field reflect.StructField, value reflect.Value) error {
-		argName := getTagOrDefault(field, tagArg)
+// argSource provides configuration parameters from command-line arguments, by the 'arg' tag.
+type argSource struct {
+	args []string // The command-line arguments, including the program name.
+}
 
-		argValue := getArgValue(argName, field.Type.Kind() == reflect.Bool)
-		if argValue == "" { // No value found for this argument.
-			return nil
-		}
+// Kind returns SourceArg.
+func (argSource) Kind() SourceKind {
+	return SourceArg
+}
+
+// Lookup returns the value of the command-line argument named by the 'arg' tag of the field,
+// or by the field name in lower case.
+func (src argSource) Lookup(field Field) (Value, bool, error) {
+	argName := field.Tag.Get(tagArg)
+	if argName == "" {
+		argName = strings.ToLower(field.Name)
+	}
+
+	argValue, argForm := getArgValue(src.args, argName, field.Type.Kind() == reflect.Bool)
+	if argValue == "" { // No value found for this argument.
+		return Value{}, false, nil
+	}
 
-		if err := setFieldByKind(field, value, argValue); err != nil {
-			return err
+	// The value may be read from a file specified as "file://path" or "@path".
+	if path, ok := cutFilePrefix(argValue); ok {
+		var err error
+		if argValue, err = readValueFile(path); err != nil {
+			return Value{}, false, fmt.Errorf("%s: %w", argForm, err)
 		}
-		*isSet[field.Name] = true
+	}
 
-		return nil
-	})
+	return Value{Raw: argValue, Location: argForm}, true, nil
 }
 
-// getArgValue scans the command-line arguments for the specified argument. For non-boolean arguments,
-// it looks for a value specified with "=" or a space. For boolean arguments, it also accepts the lack
-// of an explicitly specified value as "true".
-func getArgValue(argName string, isBool bool) string {
-	for i := 1; i < len(os.Args); i++ {
-		arg := strings.TrimLeft(os.Args[i], argPrefix)
+// cutFilePrefix returns the path from a value in the form "file://path" or "@path",
+// and reports whether the value has one of these prefixes.
+func cutFilePrefix(value string) (string, bool) {
+	if path, ok := strings.CutPrefix(value, filePrefix); ok {
+		return path, true
+	}
+
+	return strings.CutPrefix(value, fileShortPrefix)
+}
+
+// readValueFile reads the value of a configuration parameter from a file. Leading and trailing
+// whitespace, including the trailing newline, is trimmed.
+func readValueFile(path string) (string, error) {
+	if path == "" {
+		return "", errors.New("empty file path")
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(content)), nil
+}
+
+// getArgValue scans the command-line arguments, including the program name, for the specified argument.
+// For non-boolean arguments, it looks for a value specified with "=" or a space. For boolean arguments,
+// it also accepts the lack of an explicitly specified value as "true". It also returns the form in which
+// the argument was specified, such as "--arg-name".
+func getArgValue(args []string, argName string, isBool bool) (string, string) {
+	for i := 1; i < len(args); i++ {
+		arg := strings.TrimLeft(args[i], argPrefix)
+		form := args[i][:len(args[i])-len(arg)] + argName
 		equalIndex := strings.Index(arg, "=")
 
 		if equalIndex > 0 { // Value is specified with "=".
 			key := arg[:equalIndex]
 			value := arg[equalIndex+1:]
 			if key == argName {
-				return value
+				return value, form
 			}
 		} else if arg == argName { // Value is specified with a space or is missing.
-			if i+1 < len(os.Args) && !strings.HasPrefix(os.Args[i+1], argPrefix) {
-				return os.Args[i+1] // Value is specified with a space.
+			if i+1 < len(args) && !strings.HasPrefix(args[i+1], argPrefix) {
+				return args[i+1], form // Value is specified with a space.
 			} else if isBool { // Value is missing, but it's a boolean argument.
-				return "true" //nolint:goconst
+				return "true", form //nolint:goconst
 			}
 		}
 	}
 
-	return ""
+	return "", ""
 }
 
 // checkRequiredAndDepends verifies if all required and dependent configuration parameters have been set.
 // If a parameter marked 'required' is not set, or
-// if a parameter with a 'depends' tag doesn't have its dependencies met,
-// it returns an error indicating which parameter is missing.
+// if a parameter with a 'depends' tag doesn't have its dependencies met, or
+// if a parameter with a 'conflicts' tag is set together with one of the listed parameters, or
+// if a parameter with a 'requiredif' or 'requiredwith' tag is not set while its condition is met, or
+// if none of the parameters of a 'oneof_required' group is set,
+// it returns an error indicating which parameter is missing or conflicting.
 func checkRequiredAndDepends(cfg Config, isSet map[string]*bool) error {
-	cfgType := reflect.TypeOf(cfg).Elem()
+	cfgValue := reflect.ValueOf(cfg).Elem()
+	cfgType := cfgValue.Type()
+
+	// Groups of 'oneof_required' parameters, in the order of their first appearance.
+	var groups []string
+	groupFields := make(map[string][]string)
 
 	for i := 0; i < cfgType.NumField(); i++ {
 		field := cfgType.Field(i)
 
 		required := field.Tag.Get(tagRequired)
 		// If the field is marked as 'required' and not set in the 'isSet' map, return an error.
-		if required == "true" && (isSet[field.Name] == nil || !*isSet[field.Name]) {
+		if required == "true" && !isFieldSet(isSet, field.Name) {
 			return fmt.Errorf("%w: %s", ErrRequiredNotSet, field.Name)
 		}
 
@@ -323,12 +540,91 @@ func checkRequiredAndDepends(cfg Config, isSet map[string]*bool) error {
 			depends := strings.Split(dependsStr, ",")
 			for _, depend := range depends {
 				// If the dependent field is not set in the 'isSet' map, return an error.
-				if isSet[depend] == nil || !*isSet[depend] {
+				if !isFieldSet(isSet, depend) {
 					return fmt.Errorf("%w: %s", ErrDependsNotSet, depend)
 				}
 			}
 		}
+
+		conflictsStr := field.Tag.Get(tagConflicts)
+		if conflictsStr != "" && isFieldSet(isSet, field.Name) {
+			for _, conflict := range strings.Split(conflictsStr, ",") {
+				// If the field and the conflicting field are both set, return an error.
+				if isFieldSet(isSet, conflict) {
+					return fmt.Errorf("%w: %s, %s", ErrConflictsSet, field.Name, conflict)
+				}
+			}
+		}
+
+		requiredWithStr := field.Tag.Get(tagRequiredWith)
+		if requiredWithStr != "" && !isFieldSet(isSet, field.Name) {
+			for _, with := range strings.Split(requiredWithStr, ",") {
+				// If any of the listed fields is set, the field must be set too.
+				if isFieldSet(isSet, with) {
+					return fmt.Errorf("%w: %s (with %s)", ErrRequiredWithNotSet, field.Name, with)
+				}
+			}
+		}
+
+		requiredIf := field.Tag.Get(tagRequiredIf)
+		if requiredIf != "" && !isFieldSet(isSet, field.Name) {
+			matched, err := matchCondition(cfgValue, requiredIf)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", field.Name, err)
+			}
+			// If the condition is met, the field must be set.
+			if matched {
+				return fmt.Errorf("%w: %s (if %s)", ErrRequiredIfNotSet, field.Name, requiredIf)
+			}
+		}
+
+		if group := field.Tag.Get(tagOneOfRequired); group != "" {
+			if _, ok := groupFields[group]; !ok {
+				groups = append(groups, group)
+			}
+			groupFields[group] = append(groupFields[group], field.Name)
+		}
+	}
+
+	// At least one field of each 'oneof_required' group must be set.
+	for _, group := range groups {
+		isGroupSet := false
+		for _, name := range groupFields[group] {
+			if isFieldSet(isSet, name) {
+				isGroupSet = true
+				break
+			}
+		}
+		if !isGroupSet {
+			return fmt.Errorf("%w: %s %v", ErrOneOfRequiredNotSet, group, groupFields[group])
+		}
 	}
 
 	return nil
 }
+
+// isFieldSet reports whether the field with the given name has been set by any source.
+func isFieldSet(isSet map[string]*bool, name string) bool {
+	return isSet[name] != nil && *isSet[name]
+}
+
+// matchCondition reports whether a condition in the form "Field=value" is met by the configuration.
+// The value is parsed into the type of the field before comparison.
+func matchCondition(cfgValue reflect.Value, condition string) (bool, error) {
+	name, expected, ok := strings.Cut(condition, "=")
+	if !ok {
+		return false, fmt.Errorf("invalid condition: %s", condition)
+	}
+
+	field, err := lookupLoadableField(cfgValue.Type(), name)
+	if err != nil {
+		return false, fmt.Errorf("%w in condition: %s", err, condition)
+	}
+
+	expectedValue := reflect.New(field.Type).Elem()
+	if err := setFieldByKind(field, expectedValue, expected); err != nil {
+		return false, fmt.Errorf("invalid condition: %s: %w", condition, err)
+	}
+
+	return reflect.DeepEqual(cfgValue.FieldByIndex(field.Index).Interface(), expectedValue.Interface()), nil
+}
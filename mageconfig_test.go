@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -167,3 +169,246 @@ func TestLoad(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckRequiredAndDepends(t *testing.T) {
+	type testConfig struct {
+		Mode     string `default:"dev"`
+		Token    string `oneof_required:"auth"`
+		Password string `oneof_required:"auth" conflicts:"Token"`
+		User     string `requiredwith:"Password"`
+		Cert     string `requiredif:"Mode=prod"`
+	}
+
+	testCases := []struct {
+		name    string
+		isSet   []string
+		cfg     testConfig
+		wantErr error
+		errMsg  string
+	}{
+		{
+			name:  "all rules satisfied",
+			isSet: []string{"Mode", "Token"},
+			cfg:   testConfig{Mode: "dev", Token: "token"},
+		},
+		{
+			name:    "conflicting fields set",
+			isSet:   []string{"Mode", "Token", "Password", "User"},
+			cfg:     testConfig{Mode: "dev"},
+			wantErr: ErrConflictsSet,
+			errMsg:  "conflicting parameters set: Password, Token",
+		},
+		{
+			name:    "none of group set",
+			isSet:   []string{"Mode"},
+			cfg:     testConfig{Mode: "dev"},
+			wantErr: ErrOneOfRequiredNotSet,
+			errMsg:  "one of required parameters not set: auth [Token Password]",
+		},
+		{
+			name:    "required with not set",
+			isSet:   []string{"Mode", "Password"},
+			cfg:     testConfig{Mode: "dev"},
+			wantErr: ErrRequiredWithNotSet,
+			errMsg:  "parameter required with another parameter not set: User (with Password)",
+		},
+		{
+			name:    "required if not set",
+			isSet:   []string{"Mode", "Token"},
+			cfg:     testConfig{Mode: "prod"},
+			wantErr: ErrRequiredIfNotSet,
+			errMsg:  "conditionally required parameter not set: Cert (if Mode=prod)",
+		},
+		{
+			name:  "required if set",
+			isSet: []string{"Mode", "Token", "Cert"},
+			cfg:   testConfig{Mode: "prod"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			isSet := make(map[string]*bool)
+			initializeIsSet(&tc.cfg, isSet)
+			for _, name := range tc.isSet {
+				*isSet[name] = true
+			}
+
+			err := checkRequiredAndDepends(&tc.cfg, isSet)
+			if tc.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tc.wantErr)
+			assert.EqualError(t, err, tc.errMsg)
+		})
+	}
+}
+
+func TestMatchCondition(t *testing.T) {
+	type testConfig struct {
+		Mode    string
+		Retries int
+		TLS     struct{ Cert string }
+		mode    string //nolint:unused
+	}
+
+	testCases := []struct {
+		name      string
+		condition string
+		want      bool
+		wantErr   string
+	}{
+		{name: "match", condition: "Mode=prod", want: true},
+		{name: "no match", condition: "Retries=5"},
+		{name: "missing value", condition: "Mode", wantErr: "invalid condition: Mode"},
+		{name: "unknown field", condition: "Mod=prod", wantErr: `unknown field "Mod" in condition: Mod=prod`},
+		{name: "unexported field", condition: "mode=prod", wantErr: `unloadable field "mode" in condition: mode=prod`},
+		{name: "nested struct", condition: "TLS=x", wantErr: `unloadable field "TLS" in condition: TLS=x`},
+	}
+
+	cfg := testConfig{Mode: "prod", Retries: 3}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := matchCondition(reflect.ValueOf(cfg), tc.condition)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestLoadFromValueFiles(t *testing.T) {
+	type testConfig struct {
+		APIKey   string `env:"TEST_API_KEY" fileenv:"true"`
+		Password string `env:"TEST_PASSWORD"`
+		Token    string `arg:"token"`
+		Cert     string `arg:"cert"`
+		RepoURL  string `env:"TEST_REPO_URL"`
+	}
+
+	dir := t.TempDir()
+	keyFile := dir + "/api_key"
+	assert.NoError(t, os.WriteFile(keyFile, []byte("abc123\n"), 0o600))
+	certFile := dir + "/cert"
+	assert.NoError(t, os.WriteFile(certFile, []byte("  cert  "), 0o600))
+
+	testCases := []struct {
+		name       string
+		env        map[string]string
+		args       []string
+		wantConfig testConfig
+		wantErr    string
+	}{
+		{
+			name: "values read from files",
+			env: map[string]string{
+				"TEST_API_KEY_FILE":  keyFile,
+				"TEST_PASSWORD_FILE": keyFile, // Ignored without the fileenv tag.
+			},
+			args:       []string{"-token", "file://" + keyFile, "-cert=@" + certFile},
+			wantConfig: testConfig{APIKey: "abc123", Token: "abc123", Cert: "cert"},
+		},
+		{
+			name:       "URL of a field that does not resolve references",
+			env:        map[string]string{"TEST_REPO_URL": "file:///srv/git/repo.git"},
+			wantConfig: testConfig{RepoURL: "file:///srv/git/repo.git"},
+		},
+		{
+			name:       "environment variable takes precedence over file",
+			env:        map[string]string{"TEST_API_KEY": "env", "TEST_API_KEY_FILE": keyFile},
+			wantConfig: testConfig{APIKey: "env"},
+		},
+		{
+			name:    "unreadable environment file",
+			env:     map[string]string{"TEST_API_KEY_FILE": dir + "/missing"},
+			wantErr: "env source: field APIKey: TEST_API_KEY_FILE: open " + dir + "/missing: no such file or directory",
+		},
+		{
+			name:    "unreadable argument file",
+			args:    []string{"-cert=@"},
+			wantErr: "arg source: field Cert: -cert: empty file path",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			os.Args = append([]string{"cmd"}, tc.args...)
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			isSet := make(map[string]*bool)
+			raw := make(map[string]*rawValue)
+			cfg := testConfig{}
+			initializeIsSet(&cfg, isSet)
+
+			err := loadFromSource(&cfg, envSource{}, isSet, raw)
+			if err == nil {
+				err = loadFromSource(&cfg, argSource{args: os.Args}, isSet, raw)
+			}
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantConfig, cfg)
+		})
+	}
+}
+
+func TestLoadTargetSections(t *testing.T) {
+	type testConfig struct {
+		Timeout time.Duration `file:"timeout"`
+		Retries int           `file:"retries"`
+	}
+
+	testCases := []struct {
+		name       string
+		args       []string
+		opts       []Option
+		wantConfig testConfig
+		wantLine   int
+	}{
+		{
+			name:       "no target",
+			args:       []string{"-v"},
+			wantConfig: testConfig{Timeout: 10 * time.Second, Retries: 3},
+			wantLine:   1,
+		},
+		{
+			name:       "detected target",
+			args:       []string{"-v", "-t", "5m", "deploy", "-retries=2"},
+			wantConfig: testConfig{Timeout: 60 * time.Second, Retries: 2},
+			wantLine:   5,
+		},
+		{
+			name:       "target section names are case-insensitive",
+			args:       []string{"test"},
+			wantConfig: testConfig{Timeout: 10 * time.Second, Retries: 0},
+			wantLine:   1,
+		},
+		{
+			name:       "explicit target",
+			args:       []string{"build"},
+			opts:       []Option{WithTarget("Deploy")},
+			wantConfig: testConfig{Timeout: 60 * time.Second, Retries: 3},
+			wantLine:   5,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Beware: this modifies global state and is not safe for parallel test execution.
+			os.Args = append([]string{"cmd"}, tc.args...)
+			defer func() { isLoaded = false }()
+
+			cfg := testConfig{}
+			assert.NoError(t, Load(&cfg, "testdata/sections.config", tc.opts...))
+			assert.Equal(t, tc.wantConfig, cfg)
+			assert.Equal(t, fmt.Sprintf("testdata/sections.config:%d", tc.wantLine), Origins(&cfg)["Timeout"].Location)
+		})
+	}
+}
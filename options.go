@@ -0,0 +1,161 @@
+package mageconfig
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// defaultSources is the default order of sources, from the lowest to the highest precedence.
+var defaultSources = []SourceKind{SourceDefault, SourceFile, SourceGit, SourceEnv, SourceArg}
+
+// Option configures how Load reads the configuration.
+type Option func(*options)
+
+// options holds the settings of a single Load call.
+type options struct {
+	order         []SourceKind
+	customSources map[SourceKind]Source
+	target        string
+	profiles      []string
+	searchFile    bool
+	args          []string
+	help          []HelpOption
+}
+
+// WithSources sets the kinds of sources Load reads, from the lowest to the highest precedence.
+// Sources not listed are ignored. By default, the order is SourceDefault, SourceFile, SourceGit, SourceEnv,
+// SourceArg, i.e. command-line arguments win over environment variables, which win over the git
+// configuration, which wins over the configuration file.
+func WithSources(kinds ...SourceKind) Option {
+	return func(o *options) {
+		o.order = kinds
+	}
+}
+
+// WithTarget sets the name of the target whose section of the configuration file overrides the global
+// values. By default, the target is detected from the command-line arguments.
+func WithTarget(name string) Option {
+	return func(o *options) {
+		o.target = name
+	}
+}
+
+// WithProfiles sets the active profiles, such as "prod", whose configuration files and sections override
+// the configuration file in order. By default, the profiles are read from the --profile command-line
+// argument or the MAGE_PROFILE environment variable, as a comma-separated list.
+func WithProfiles(names ...string) Option {
+	return func(o *options) {
+		o.profiles = names
+	}
+}
+
+// WithFileSearch enables the search of a configuration file with a relative path in the current working
+// directory, the root of the project (the nearest parent directory containing magefile.go, go.mod or
+// .git), and the user and system configuration directories, as described by the XDG specification.
+// This allows running mage from a subdirectory. The files actually read are returned by ConfigFiles.
+func WithFileSearch() Option {
+	return func(o *options) {
+		o.searchFile = true
+	}
+}
+
+// WithHelp configures the help written by Load when the -help or --help argument is passed, e.g. with
+// a compact layout or a custom footer.
+func WithHelp(opts ...HelpOption) Option {
+	return func(o *options) {
+		o.help = append(o.help, opts...)
+	}
+}
+
+// WithCustomSource adds a custom source, such as a reader of a settings table. It is read in the position
+// of its kind given by WithSources, or after all other sources if its kind is not listed there.
+// Its kind must differ from the kinds of the built-in sources, otherwise Load returns an error.
+func WithCustomSource(src Source) Option {
+	return func(o *options) {
+		o.customSources[src.Kind()] = src
+	}
+}
+
+// newOptions applies the options to the default settings.
+func newOptions(opts []Option) *options {
+	o := &options{
+		order:         defaultSources,
+		customSources: make(map[SourceKind]Source),
+		target:        detectTarget(),
+		profiles:      detectProfiles(),
+		// Keep the arguments, as DropArgsAfterTarget removes them after the first load.
+		args: append([]string(nil), os.Args...),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// newSources returns the sources to read, from the lowest to the highest precedence.
+// Custom sources implementing the SourceChecker interface check the configuration struct.
+func (o *options) newSources(cfg Config, file string) ([]Source, error) {
+	// Custom sources not listed explicitly are read last, in the order of their kinds for determinism.
+	var unlisted []SourceKind
+	for kind := range o.customSources {
+		if containsSource(defaultSources, kind) || kind == SourceNone || kind == SourceComputed {
+			return nil, fmt.Errorf("custom source kind %q conflicts with a built-in source", kind)
+		}
+		if !containsSource(o.order, kind) {
+			unlisted = append(unlisted, kind)
+		}
+	}
+	sort.Slice(unlisted, func(i, j int) bool { return unlisted[i] < unlisted[j] })
+	order := append(o.order[:len(o.order):len(o.order)], unlisted...)
+
+	sources := make([]Source, 0, len(order))
+	for i, kind := range order {
+		if containsSource(order[:i], kind) {
+			return nil, fmt.Errorf("duplicate source: %s", kind)
+		}
+
+		switch kind {
+		case SourceDefault:
+			sources = append(sources, defaultSource{})
+		case SourceFile:
+			if o.searchFile {
+				file = findConfigFile(file)
+			}
+			fileSrc, err := newFileSource(file, o.target, o.profiles)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, fileSrc)
+		case SourceGit:
+			sources = append(sources, newGitSource())
+		case SourceEnv:
+			sources = append(sources, envSource{})
+		case SourceArg:
+			sources = append(sources, argSource{args: o.args})
+		default:
+			src, ok := o.customSources[kind]
+			if !ok {
+				return nil, fmt.Errorf("unknown source: %s", kind)
+			}
+			sources = append(sources, src)
+		}
+	}
+
+	if err := checkSources(cfg, sources); err != nil {
+		return nil, err
+	}
+
+	return sources, nil
+}
+
+// containsSource reports whether the list of kinds of sources contains the kind.
+func containsSource(kinds []SourceKind, kind SourceKind) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package mageconfig
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadWithOptions(t *testing.T) {
+	type testConfig struct {
+		Region  string `arg:"region" env:"TEST_REGION" default:"eu"`
+		Token   string `arg:"token" env:"TEST_TOKEN" sources:"arg"`
+		Profile string `arg:"profile" env:"TEST_PROFILE" file:"profile"`
+	}
+
+	settings := NewSource("settings", func(field Field) (Value, bool, error) {
+		if field.Name == "Profile" {
+			return Value{Raw: "settings"}, true, nil
+		}
+		return Value{}, false, nil
+	})
+
+	testCases := []struct {
+		name       string
+		opts       []Option
+		env        map[string]string
+		args       []string
+		wantConfig testConfig
+		wantErr    string
+	}{
+		{
+			name: "default order",
+			env:  map[string]string{"TEST_REGION": "us", "TEST_TOKEN": "env"},
+			args: []string{"-region=ap"},
+			wantConfig: testConfig{
+				Region: "ap",
+			},
+		},
+		{
+			name: "environment wins over arguments",
+			opts: []Option{WithSources(SourceDefault, SourceArg, SourceEnv)},
+			env:  map[string]string{"TEST_REGION": "us"},
+			args: []string{"-region=ap", "-token=arg"},
+			wantConfig: testConfig{
+				Region: "us",
+				Token:  "arg",
+			},
+		},
+		{
+			name: "environment ignored",
+			opts: []Option{WithSources(SourceDefault, SourceArg)},
+			env:  map[string]string{"TEST_REGION": "us", "TEST_PROFILE": "env"},
+			wantConfig: testConfig{
+				Region: "eu",
+			},
+		},
+		{
+			name: "custom source read last by default",
+			opts: []Option{WithCustomSource(settings)},
+			env:  map[string]string{"TEST_PROFILE": "env"},
+			wantConfig: testConfig{
+				Region:  "eu",
+				Profile: "settings",
+			},
+		},
+		{
+			name: "custom source in explicit position",
+			opts: []Option{
+				WithCustomSource(settings),
+				WithSources(SourceDefault, "settings", SourceEnv, SourceArg),
+			},
+			env: map[string]string{"TEST_PROFILE": "env"},
+			wantConfig: testConfig{
+				Region:  "eu",
+				Profile: "env",
+			},
+		},
+		{
+			name:    "unknown source",
+			opts:    []Option{WithSources(SourceDefault, "settings")},
+			wantErr: "unknown source: settings",
+		},
+		{
+			name:    "duplicate source",
+			opts:    []Option{WithSources(SourceEnv, SourceEnv)},
+			wantErr: "duplicate source: env",
+		},
+		{
+			name: "custom source of a built-in kind",
+			opts: []Option{WithCustomSource(NewSource(SourceEnv, func(field Field) (Value, bool, error) {
+				return Value{Raw: "custom"}, true, nil
+			}))},
+			wantErr: `custom source kind "env" conflicts with a built-in source`,
+		},
+		{
+			name: "custom source error",
+			opts: []Option{WithCustomSource(NewSource("broken", func(field Field) (Value, bool, error) {
+				return Value{}, false, errors.New("unavailable")
+			}))},
+			wantErr: "broken source: field Region: unavailable",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Beware: this modifies global state and is not safe for parallel test execution.
+			os.Args = append([]string{"cmd"}, tc.args...)
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			defer func() { isLoaded = false }()
+
+			cfg := testConfig{}
+			err := Load(&cfg, "", tc.opts...)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantConfig, cfg)
+		})
+	}
+}
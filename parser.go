@@ -3,6 +3,7 @@ package mageconfig
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -19,7 +20,7 @@ func getTagOrDefault(field reflect.StructField, tag string) string {
 	return value
 }
 
-// setFields iterates over each field in the given configuration and applies the setValue function to it.
+// setFields iterates over each loadable field in the given configuration and applies the setValue function to it.
 // The setValue function is responsible for assigning a value to the field.
 // This function is used to abstract the common pattern of iterating over struct fields.
 func setFields(cfg Config, setValue func(field reflect.StructField, value reflect.Value) error) error {
@@ -32,6 +33,9 @@ func setFields(cfg Config, setValue func(field reflect.StructField, value reflec
 	// Iterate over each field in the struct and apply the setValue function to the current field.
 	for i := 0; i < cfgType.NumField(); i++ {
 		field := cfgType.Field(i)
+		if !isLoadable(field) {
+			continue
+		}
 		value := cfgValue.Field(i)
 		if err := setValue(field, value); err != nil {
 			return err
@@ -42,8 +46,15 @@ func setFields(cfg Config, setValue func(field reflect.StructField, value reflec
 }
 
 // setFieldByKind assigns a value to a struct field based on its kind (type).
-// It supports slice, map, and basic types.
-func setFieldByKind(field reflect.StructField, value reflect.Value, strVal string) error {
+// It supports slice, map, and basic types. Errors for secret fields are redacted.
+func setFieldByKind(field reflect.StructField, value reflect.Value, strVal string) (err error) {
+	// Parse errors usually contain the raw value, so hide it for secret fields.
+	defer func() {
+		if err != nil && isSecret(field) {
+			err = &secretError{field: field.Name, err: err}
+		}
+	}()
+
 	switch field.Type.Kind() {
 	case reflect.Slice:
 		// Handle slice types: split the string value into elements, create a new slice with the appropriate type and size
@@ -105,22 +116,110 @@ func parseStringToType(s string, t reflect.Type) (reflect.Value, error) {
 		return reflect.ValueOf(v), err
 	}
 
+	var (
+		v   any
+		err error
+	)
+	// Values are parsed with the bit size of the type, so that values out of its range are rejected,
+	// and converted to the type, so that they can be assigned to fields of sized or named types.
 	switch t.Kind() {
 	case reflect.Bool:
-		v, err := strconv.ParseBool(s)
-		return reflect.ValueOf(v), err
+		v, err = strconv.ParseBool(s)
 	case reflect.Int, reflect.Int32, reflect.Int64:
-		v, err := strconv.ParseInt(s, 10, 64)
-		return reflect.ValueOf(int(v)), err
+		v, err = strconv.ParseInt(s, 10, t.Bits())
 	case reflect.Uint, reflect.Uint32, reflect.Uint64:
-		v, err := strconv.ParseUint(s, 10, 64)
-		return reflect.ValueOf(uint(v)), err
+		v, err = strconv.ParseUint(s, 10, t.Bits())
 	case reflect.Float32, reflect.Float64:
-		v, err := strconv.ParseFloat(s, 64)
-		return reflect.ValueOf(v), err
+		v, err = strconv.ParseFloat(s, t.Bits())
+	case reflect.String:
+		v = s
+	default:
+		return reflect.Value{}, fmt.Errorf("unsupported type")
+	}
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	return reflect.ValueOf(v).Convert(t), nil
+}
+
+// formatFieldValue formats the value of a struct field as a string, which setFieldByKind parses back
+// into the same value. Slice elements and map pairs are separated by comma, and map keys are sorted.
+func formatFieldValue(value reflect.Value) string {
+	switch value.Kind() {
+	case reflect.Slice:
+		elems := make([]string, value.Len())
+		for i := range elems {
+			elems[i] = formatBasicValue(value.Index(i))
+		}
+		return strings.Join(elems, sliceSeparator)
+
+	case reflect.Map:
+		pairs := make([]string, 0, value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			pairs = append(pairs, iter.Key().String()+kvSeparator+formatBasicValue(iter.Value()))
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, sliceSeparator)
+
+	default:
+		return formatBasicValue(value)
+	}
+}
+
+// formatBasicValue formats a value of a basic type as a string, which parseStringToType parses back
+// into the same value.
+func formatBasicValue(v reflect.Value) string {
+	switch v.Type() {
+	case reflect.TypeOf(time.Duration(0)):
+		return time.Duration(v.Int()).String()
+	case reflect.TypeOf(time.Time{}):
+		return v.Interface().(time.Time).Format(time.RFC3339)
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
 	case reflect.String:
-		return reflect.ValueOf(s), nil
+		return v.String()
 	}
 
-	return reflect.Value{}, fmt.Errorf("unsupported type")
+	return fmt.Sprint(v.Interface())
+}
+
+// fieldData converts the value of a struct field into plain data for the JSON and YAML encoders:
+// durations and times are formatted as strings, slices and maps are converted element by element,
+// and other values are kept as they are.
+func fieldData(value reflect.Value) interface{} {
+	switch value.Type() {
+	case reflect.TypeOf(time.Duration(0)), reflect.TypeOf(time.Time{}):
+		return formatBasicValue(value)
+	}
+
+	switch value.Kind() {
+	case reflect.Slice:
+		elems := make([]interface{}, value.Len())
+		for i := range elems {
+			elems[i] = fieldData(value.Index(i))
+		}
+		return elems
+
+	case reflect.Map:
+		pairs := make(map[string]interface{}, value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			pairs[iter.Key().String()] = fieldData(iter.Value())
+		}
+		return pairs
+
+	default:
+		return value.Interface()
+	}
 }
@@ -256,6 +256,34 @@ func TestParseStringToType(t *testing.T) {
 			value: reflect.Value{},
 			err:   errors.New("strconv.ParseFloat: parsing \"invalid\": invalid syntax"),
 		},
+		{
+			name:  "parse string to int32",
+			s:     "42",
+			t:     reflect.TypeOf(int32(0)),
+			value: reflect.ValueOf(int32(42)),
+			err:   nil,
+		},
+		{
+			name:  "parse out of range string to int32",
+			s:     "4294967296",
+			t:     reflect.TypeOf(int32(0)),
+			value: reflect.Value{},
+			err:   errors.New("strconv.ParseInt: parsing \"4294967296\": value out of range"),
+		},
+		{
+			name:  "parse string to uint64",
+			s:     "42",
+			t:     reflect.TypeOf(uint64(0)),
+			value: reflect.ValueOf(uint64(42)),
+			err:   nil,
+		},
+		{
+			name:  "parse string to float32",
+			s:     "0.5",
+			t:     reflect.TypeOf(float32(0)),
+			value: reflect.ValueOf(float32(0.5)),
+			err:   nil,
+		},
 		{
 			name:  "parse string to string",
 			s:     "test",
@@ -286,3 +314,48 @@ func TestParseStringToType(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatFieldValue(t *testing.T) {
+	type testConfig struct {
+		Bool     bool
+		Int      int
+		Uint     uint
+		Float    float64
+		Time     time.Time
+		Ints     []int
+		Durs     map[string]time.Duration
+		Duration time.Duration
+	}
+
+	cfg := testConfig{
+		Bool:     true,
+		Int:      -3,
+		Uint:     7,
+		Float:    1.5,
+		Time:     time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Ints:     []int{1, 2},
+		Durs:     map[string]time.Duration{"b": time.Second, "a": time.Minute},
+		Duration: 90 * time.Second,
+	}
+
+	expected := map[string]string{
+		"Bool":     "true",
+		"Int":      "-3",
+		"Uint":     "7",
+		"Float":    "1.5",
+		"Time":     "2024-01-02T03:04:05Z",
+		"Ints":     "1,2",
+		"Durs":     "a:1m0s,b:1s",
+		"Duration": "1m30s",
+	}
+
+	parsed := testConfig{}
+	parsedValue := reflect.ValueOf(&parsed).Elem()
+	err := setFields(&cfg, func(field reflect.StructField, value reflect.Value) error {
+		str := formatFieldValue(value)
+		assert.Equal(t, expected[field.Name], str, field.Name)
+		return setFieldByKind(field, parsedValue.FieldByName(field.Name), str)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, cfg, parsed)
+}
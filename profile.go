@@ -0,0 +1,48 @@
+package mageconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Names used to select the active profiles.
+const (
+	profileArg           = "profile"      // The command-line argument listing the active profiles.
+	profileEnv           = "MAGE_PROFILE" // The environment variable listing the active profiles.
+	profileSectionPrefix = "profile."     // The prefix of the configuration file sections of profiles.
+)
+
+// detectProfiles returns the active profiles listed in the --profile command-line argument or,
+// if it is not specified, in the MAGE_PROFILE environment variable.
+func detectProfiles() []string {
+	value, _ := getArgValue(os.Args, profileArg, false)
+	if value == "" {
+		value = os.Getenv(profileEnv)
+	}
+
+	return splitProfiles(value)
+}
+
+// splitProfiles splits a comma-separated list of profiles, skipping empty names.
+func splitProfiles(value string) []string {
+	var profiles []string
+	for _, profile := range strings.Split(value, sliceSeparator) {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			profiles = append(profiles, profile)
+		}
+	}
+
+	return profiles
+}
+
+// profileFile returns the path of the configuration file of a profile, with the profile name inserted
+// before the extension of the base file: "mage.config" becomes "mage.prod.config".
+func profileFile(file, profile string) string {
+	ext := filepath.Ext(file)
+	if ext == "" || ext == file || strings.HasSuffix(file, string(filepath.Separator)+ext) {
+		return file + "." + profile
+	}
+
+	return strings.TrimSuffix(file, ext) + "." + profile + ext
+}
@@ -0,0 +1,102 @@
+package mageconfig
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfileFile(t *testing.T) {
+	assert.Equal(t, "mage.prod.config", profileFile("mage.config", "prod"))
+	assert.Equal(t, "conf/app.prod.yaml", profileFile("conf/app.yaml", "prod"))
+	assert.Equal(t, "config.prod", profileFile("config", "prod"))
+	assert.Equal(t, "conf/.mageconfig.prod", profileFile("conf/.mageconfig", "prod"))
+}
+
+func TestLoadProfiles(t *testing.T) {
+	type testConfig struct {
+		URL     string        `file:"url"`
+		Retries int           `file:"retries"`
+		Timeout time.Duration `file:"timeout"`
+	}
+
+	testCases := []struct {
+		name         string
+		env          map[string]string
+		args         []string
+		opts         []Option
+		wantConfig   testConfig
+		wantProfiles []string
+	}{
+		{
+			name:       "no profile",
+			wantConfig: testConfig{URL: "http://localhost", Retries: 1, Timeout: 5 * time.Second},
+		},
+		{
+			name:         "profile file from argument",
+			args:         []string{"deploy", "--profile=prod"},
+			wantConfig:   testConfig{URL: "https://example.com", Retries: 1, Timeout: 60 * time.Second},
+			wantProfiles: []string{"prod"},
+		},
+		{
+			name:         "profile section from environment",
+			env:          map[string]string{"MAGE_PROFILE": "staging"},
+			wantConfig:   testConfig{URL: "https://staging.example.com", Retries: 2, Timeout: 5 * time.Second},
+			wantProfiles: []string{"staging"},
+		},
+		{
+			name:         "multiple profiles, argument wins over environment",
+			env:          map[string]string{"MAGE_PROFILE": "dev"},
+			args:         []string{"-profile", "staging, prod"},
+			wantConfig:   testConfig{URL: "https://example.com", Retries: 2, Timeout: 5 * time.Second},
+			wantProfiles: []string{"staging", "prod"},
+		},
+		{
+			name:         "explicit profiles",
+			env:          map[string]string{"MAGE_PROFILE": "prod"},
+			opts:         []Option{WithProfiles("staging")},
+			wantConfig:   testConfig{URL: "https://staging.example.com", Retries: 2, Timeout: 5 * time.Second},
+			wantProfiles: []string{"staging"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Beware: this modifies global state and is not safe for parallel test execution.
+			os.Args = append([]string{"cmd"}, tc.args...)
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			defer func() { isLoaded = false }()
+
+			cfg := testConfig{}
+			assert.NoError(t, Load(&cfg, "testdata/profiles.config", tc.opts...))
+			assert.Equal(t, tc.wantConfig, cfg)
+			assert.Equal(t, tc.wantProfiles, ActiveProfiles(&cfg))
+		})
+	}
+}
+
+func TestExplainProfiles(t *testing.T) {
+	type testConfig struct {
+		URL string `file:"url"`
+	}
+
+	// Beware: this modifies global state and is not safe for parallel test execution.
+	os.Args = []string{"cmd", "--profile=prod"}
+	defer func() { isLoaded = false }()
+
+	cfg := testConfig{}
+	assert.NoError(t, Load(&cfg, "testdata/profiles.config"))
+
+	var buf bytes.Buffer
+	assert.NoError(t, Explain(&buf, &cfg))
+	assert.Equal(t, ""+
+		"Configuration files: testdata/profiles.config, testdata/profiles.prod.config\n"+
+		"Active profiles: prod\n\n"+
+		"FIELD  VALUE                SOURCE\n"+
+		"URL    https://example.com  file testdata/profiles.prod.config:1\n", buf.String())
+}
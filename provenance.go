@@ -0,0 +1,215 @@
+package mageconfig
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+// SourceKind identifies the kind of source the value of a configuration field came from.
+type SourceKind string
+
+// Kinds of sources of configuration values.
+const (
+	SourceNone     SourceKind = ""         // The value was not set by any source.
+	SourceDefault  SourceKind = "default"  // The value came from the 'default' tag.
+	SourceFile     SourceKind = "file"     // The value came from the configuration file.
+	SourceGit      SourceKind = "git"      // The value came from the git configuration.
+	SourceEnv      SourceKind = "env"      // The value came from an environment variable.
+	SourceArg      SourceKind = "arg"      // The value came from a command-line argument.
+	SourceComputed SourceKind = "computed" // The value was computed by the SetDefaults method.
+)
+
+// Origin describes where the effective value of a configuration field came from.
+type Origin struct {
+	// Source is the kind of source that provided the value.
+	Source SourceKind
+	// Location is the place of the value within the source: "path:line" for the configuration file
+	// and the git configuration, the variable name for environment variables, and the argument as
+	// specified for command-line arguments, such as "--api-key". It is empty for default and computed
+	// values.
+	Location string
+}
+
+// String returns the source and location of the value, such as "env API_KEY".
+func (o Origin) String() string {
+	if o.Source == SourceNone {
+		return "not set"
+	}
+	if o.Location == "" {
+		return string(o.Source)
+	}
+
+	return string(o.Source) + " " + o.Location
+}
+
+// loadReport describes how a configuration was loaded.
+type loadReport struct {
+	origins  map[string]Origin
+	profiles []string
+	files    []string
+
+	// The arguments of Load and the configuration as passed to it, used by Watch to reload the configuration.
+	file    string
+	options *options
+	initial Config
+}
+
+// Reports of the loaded configurations.
+var (
+	reportsMu sync.RWMutex
+	reports   = make(map[Config]*loadReport)
+)
+
+// newReport returns the report of a configuration loaded from the file with the options: the origins
+// of the values, the active profiles and the configuration files read by the sources.
+func newReport(file string, o *options, raw map[string]*rawValue, sources []Source) *loadReport {
+	fieldOrigins := make(map[string]Origin, len(raw))
+	for name, rv := range raw {
+		fieldOrigins[name] = rv.origin
+	}
+
+	var files []string
+	for _, src := range sources {
+		if fileSrc, ok := src.(*fileSource); ok {
+			files = append(files, fileSrc.files...)
+		}
+	}
+
+	return &loadReport{origins: fieldOrigins, profiles: o.profiles, files: files, file: file, options: o}
+}
+
+// storeReport keeps the report of the loaded configuration.
+func storeReport(cfg Config, report *loadReport) {
+	reportsMu.Lock()
+	defer reportsMu.Unlock()
+	reports[cfg] = report
+}
+
+// lookupReport returns the report of the loaded configuration, or nil if it was not loaded.
+func lookupReport(cfg Config) *loadReport {
+	reportsMu.RLock()
+	defer reportsMu.RUnlock()
+
+	return reports[cfg]
+}
+
+// Origins returns where the effective value of each field of a configuration loaded by Load came from,
+// by field name. Fields not set by any source are omitted. It returns nil if the configuration was not loaded.
+func Origins(cfg Config) map[string]Origin {
+	report := lookupReport(cfg)
+	if report == nil {
+		return nil
+	}
+
+	result := make(map[string]Origin, len(report.origins))
+	for name, origin := range report.origins {
+		result[name] = origin
+	}
+
+	return result
+}
+
+// ActiveProfiles returns the profiles that were active when the configuration was loaded by Load.
+func ActiveProfiles(cfg Config) []string {
+	report := lookupReport(cfg)
+	if report == nil {
+		return nil
+	}
+
+	return append([]string(nil), report.profiles...)
+}
+
+// ConfigFiles returns the configuration files actually read when the configuration was loaded by Load:
+// the configuration file, as found by the search enabled with WithFileSearch, and the profile files.
+// Missing files are omitted.
+func ConfigFiles(cfg Config) []string {
+	report := lookupReport(cfg)
+	if report == nil {
+		return nil
+	}
+
+	return append([]string(nil), report.files...)
+}
+
+// Explain writes the effective value of each field of a configuration loaded by Load, together with
+// where it came from, as an aligned table preceded by the configuration files read and the active profiles,
+// if any. Secret values are redacted. It is meant for targets such as
+// "mage config:explain" that help to debug differences between environments.
+func Explain(w io.Writer, cfg Config) error {
+	fieldOrigins := Origins(cfg)
+	cfgValue := reflect.ValueOf(Redacted(cfg))
+	if cfgValue.Kind() != reflect.Pointer || cfgValue.Elem().Kind() != reflect.Struct {
+		return errors.New("config must be a pointer to a struct")
+	}
+
+	if files := ConfigFiles(cfg); len(files) > 0 {
+		if _, err := fmt.Fprintf(w, "Configuration files: %s\n", strings.Join(files, ", ")); err != nil {
+			return err
+		}
+	}
+	if profiles := ActiveProfiles(cfg); len(profiles) > 0 {
+		if _, err := fmt.Fprintf(w, "Active profiles: %s\n", strings.Join(profiles, ", ")); err != nil {
+			return err
+		}
+	}
+	if len(ConfigFiles(cfg)) > 0 || len(ActiveProfiles(cfg)) > 0 {
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+
+	return writeTable(w, cfgValue.Interface(), fieldOrigins, true)
+}
+
+// writeTable writes the value of each field of the configuration as an aligned table, with the origins
+// of the values if withOrigins is set.
+func writeTable(w io.Writer, cfg Config, fieldOrigins map[string]Origin, withOrigins bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if withOrigins {
+		fmt.Fprintln(tw, "FIELD\tVALUE\tSOURCE")
+	} else {
+		fmt.Fprintln(tw, "FIELD\tVALUE")
+	}
+	err := setFields(cfg, func(field reflect.StructField, value reflect.Value) error {
+		var err error
+		if withOrigins {
+			_, err = fmt.Fprintf(tw, "%s\t%v\t%s\n", field.Name, value.Interface(), fieldOrigins[field.Name])
+		} else {
+			_, err = fmt.Fprintf(tw, "%s\t%v\n", field.Name, value.Interface())
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return tw.Flush()
+}
+
+// SetBy returns the kind of source the effective value of the named field of a configuration loaded
+// by Load came from, or SourceNone if the field was not set or the configuration was not loaded.
+func SetBy(cfg Config, field string) SourceKind {
+	report := lookupReport(cfg)
+	if report == nil {
+		return SourceNone
+	}
+
+	return report.origins[field].Source
+}
+
+// IsSet reports whether the named field of a configuration loaded by Load was set explicitly, i.e. by
+// a source other than its default value or the SetDefaults method. It allows targets, for example, to
+// override a remote setting only when the user explicitly passed a flag.
+func IsSet(cfg Config, field string) bool {
+	switch SetBy(cfg, field) {
+	case SourceNone, SourceDefault, SourceComputed:
+		return false
+	}
+
+	return true
+}
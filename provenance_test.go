@@ -0,0 +1,85 @@
+package mageconfig
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrigins(t *testing.T) {
+	// Beware: this modifies global state and is not safe for parallel test execution.
+	os.Args = []string{"cmd", "--field1", "arg1", "-dfield0"}
+	t.Setenv("FIELD5", "env5")
+	defer func() { isLoaded = false }()
+
+	cfg := TestConfig{}
+	assert.Nil(t, Origins(&cfg))
+	assert.NoError(t, Load(&cfg, "testdata/config.file"))
+
+	assert.Equal(t, map[string]Origin{
+		"DField0": {Source: SourceArg, Location: "-dfield0"},
+		"Field0":  {Source: SourceDefault},
+		"Field1":  {Source: SourceArg, Location: "--field1"},
+		"Field2":  {Source: SourceDefault},
+		"Field3":  {Source: SourceDefault},
+		"Field4":  {Source: SourceFile, Location: "testdata/config.file:1"},
+		"Field5":  {Source: SourceEnv, Location: "FIELD5"},
+		"Field6":  {Source: SourceFile, Location: "testdata/config.file:3"},
+	}, Origins(&cfg))
+}
+
+func TestIsSetAndSetBy(t *testing.T) {
+	// Beware: this modifies global state and is not safe for parallel test execution.
+	os.Args = []string{"cmd", "--field1", "arg1", "-dfield0"}
+	t.Setenv("FIELD6", "env6")
+	defer func() { isLoaded = false }()
+
+	cfg := TestConfig{}
+	assert.False(t, IsSet(&cfg, "Field1"))
+	assert.Equal(t, SourceNone, SetBy(&cfg, "Field1"))
+	assert.NoError(t, Load(&cfg, ""))
+
+	testCases := []struct {
+		field     string
+		wantIsSet bool
+		wantSetBy SourceKind
+	}{
+		{field: "Field1", wantIsSet: true, wantSetBy: SourceArg},
+		{field: "Field6", wantIsSet: true, wantSetBy: SourceEnv},
+		{field: "Field2", wantIsSet: false, wantSetBy: SourceDefault},
+		{field: "DField1", wantIsSet: false, wantSetBy: SourceNone},
+		{field: "Unknown", wantIsSet: false, wantSetBy: SourceNone},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.field, func(t *testing.T) {
+			assert.Equal(t, tc.wantIsSet, IsSet(&cfg, tc.field))
+			assert.Equal(t, tc.wantSetBy, SetBy(&cfg, tc.field))
+		})
+	}
+}
+
+func TestExplain(t *testing.T) {
+	type testConfig struct {
+		URL     string `arg:"url" default:"https://example.com"`
+		APIKey  string `arg:"api-key" secret:"true"`
+		Retries int    `arg:"retries"`
+	}
+
+	// Beware: this modifies global state and is not safe for parallel test execution.
+	os.Args = []string{"cmd", "--api-key=abc123"}
+	defer func() { isLoaded = false }()
+
+	cfg := testConfig{}
+	assert.NoError(t, Load(&cfg, ""))
+
+	var buf bytes.Buffer
+	assert.NoError(t, Explain(&buf, &cfg))
+	assert.Equal(t, ""+
+		"FIELD    VALUE                SOURCE\n"+
+		"URL      https://example.com  default\n"+
+		"APIKey   ******               arg --api-key\n"+
+		"Retries  0                    not set\n", buf.String())
+}
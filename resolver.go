@@ -0,0 +1,147 @@
+package mageconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	tagResolve      = "resolve" // Specifies whether secret references in the values of the parameter are resolved.
+	schemeSeparator = "://"     // The separator between the scheme and the rest of a secret reference.
+)
+
+// ErrSecretResolve is the error returned when a secret reference cannot be resolved.
+var ErrSecretResolve = errors.New("resolve secret")
+
+// SecretResolveTimeout limits the time a SecretResolver may take to resolve a single reference.
+var SecretResolveTimeout = 10 * time.Second
+
+// SecretResolver resolves references to secrets, such as "vault://secret/data/app#token", into their values.
+// Values of fields tagged with 'secret:"true"' or 'resolve:"true"' whose scheme matches a registered resolver
+// are resolved before being parsed. Values of other fields, such as "file:///srv/repo.git", are used as is.
+// The errors returned by Resolve must not contain the secret value.
+type SecretResolver interface {
+	// Resolve returns the value the reference points to. The reference is passed without the scheme,
+	// e.g. "secret/data/app#token" for "vault://secret/data/app#token".
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// SecretResolverFunc is an adapter to allow the use of ordinary functions as secret resolvers.
+type SecretResolverFunc func(ctx context.Context, ref string) (string, error)
+
+// Resolve calls f(ctx, ref).
+func (f SecretResolverFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+// Registry of secret resolvers by their URI scheme, with the built-in "cmd" and "file" resolvers.
+var (
+	resolversMu sync.RWMutex
+	resolvers   = map[string]SecretResolver{
+		"cmd":  SecretResolverFunc(resolveCommand),
+		"file": SecretResolverFunc(resolveFile),
+	}
+)
+
+// RegisterSecretResolver registers a resolver for values with the given URI scheme, such as "vault".
+// It replaces a resolver previously registered for the same scheme, including the built-in ones.
+// Registering a nil resolver removes the scheme.
+func RegisterSecretResolver(scheme string, r SecretResolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+
+	if r == nil {
+		delete(resolvers, scheme)
+		return
+	}
+	resolvers[scheme] = r
+}
+
+// lookupResolver returns the resolver registered for the scheme of the value, and the reference without the scheme.
+func lookupResolver(value string) (SecretResolver, string, string) {
+	scheme, ref, ok := strings.Cut(value, schemeSeparator)
+	if !ok {
+		return nil, "", ""
+	}
+
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+
+	return resolvers[scheme], scheme, ref
+}
+
+// resolvesReferences reports whether secret references in the values of the field are resolved,
+// i.e. whether the field is marked with the 'secret' or 'resolve' tag.
+func resolvesReferences(field reflect.StructField) bool {
+	return isSecret(field) || field.Tag.Get(tagResolve) == "true"
+}
+
+// isSecretReference reports whether the value of the field is resolved by a registered secret resolver.
+func isSecretReference(field reflect.StructField, value string) bool {
+	if !resolvesReferences(field) {
+		return false
+	}
+	resolver, _, _ := lookupResolver(value)
+	return resolver != nil
+}
+
+// setFieldValue resolves the value if it is a secret reference of a field that resolves references,
+// and assigns it to the struct field. Parse errors of resolved values are redacted as if the field were secret.
+func setFieldValue(field reflect.StructField, value reflect.Value, strVal string) error {
+	if !resolvesReferences(field) {
+		return setFieldByKind(field, value, strVal)
+	}
+	resolver, scheme, ref := lookupResolver(strVal)
+	if resolver == nil {
+		return setFieldByKind(field, value, strVal)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), SecretResolveTimeout)
+	defer cancel()
+
+	resolved, err := resolver.Resolve(ctx, ref)
+	if err != nil {
+		return fmt.Errorf("%w: field %s: %s%s: %w", ErrSecretResolve, field.Name, scheme, schemeSeparator, err)
+	}
+
+	if err := setFieldByKind(field, value, resolved); err != nil {
+		var secretErr *secretError
+		if errors.As(err, &secretErr) {
+			return err
+		}
+		return &secretError{field: field.Name, err: err}
+	}
+
+	return nil
+}
+
+// resolveCommand runs the command with its arguments separated by spaces, e.g. "pass show api-key",
+// and returns its trimmed standard output. The command is not run through a shell.
+func resolveCommand(ctx context.Context, ref string) (string, error) {
+	args := strings.Fields(ref)
+	if len(args) == 0 {
+		return "", errors.New("empty command")
+	}
+
+	// The standard error is discarded, as it may contain the secret value.
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stderr = io.Discard
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", args[0], err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// resolveFile returns the trimmed content of the file.
+func resolveFile(_ context.Context, ref string) (string, error) {
+	return readValueFile(ref)
+}
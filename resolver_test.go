@@ -0,0 +1,126 @@
+package mageconfig
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetFieldValue(t *testing.T) {
+	type testConfig struct {
+		Token   string `secret:"true"`
+		Retries int    `resolve:"true"`
+		URL     string
+	}
+
+	RegisterSecretResolver("mock", SecretResolverFunc(func(_ context.Context, ref string) (string, error) {
+		switch ref {
+		case "token":
+			return "s3cr3t", nil
+		case "retries":
+			return "three", nil
+		}
+		return "", errors.New("not found")
+	}))
+	defer RegisterSecretResolver("mock", nil)
+
+	file := t.TempDir() + "/token"
+	assert.NoError(t, os.WriteFile(file, []byte("from-file\n"), 0o600))
+
+	testCases := []struct {
+		name    string
+		field   string
+		value   string
+		want    any
+		wantErr string
+	}{
+		{
+			name:  "plain value",
+			field: "Token",
+			value: "plain",
+			want:  "plain",
+		},
+		{
+			name:  "unregistered scheme",
+			field: "Token",
+			value: "https://example.com",
+			want:  "https://example.com",
+		},
+		{
+			name:  "mock resolver",
+			field: "Token",
+			value: "mock://token",
+			want:  "s3cr3t",
+		},
+		{
+			name:  "file resolver",
+			field: "Token",
+			value: "file://" + file,
+			want:  "from-file",
+		},
+		{
+			name:  "command resolver",
+			field: "Token",
+			value: "cmd://echo from-command",
+			want:  "from-command",
+		},
+		{
+			name:  "field that does not resolve references",
+			field: "URL",
+			value: "cmd://echo from-command",
+			want:  "cmd://echo from-command",
+		},
+		{
+			name:    "resolver error",
+			field:   "Token",
+			value:   "mock://missing",
+			wantErr: "resolve secret: field Token: mock://: not found",
+		},
+		{
+			name:    "command error",
+			field:   "Token",
+			value:   "cmd://false",
+			wantErr: "resolve secret: field Token: cmd://: false: exit status 1",
+		},
+		{
+			name:    "resolved value is redacted from parse errors",
+			field:   "Retries",
+			value:   "mock://retries",
+			wantErr: "parse field: invalid value of secret field Retries",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := testConfig{}
+			field, _ := reflect.TypeOf(cfg).FieldByName(tc.field)
+			value := reflect.ValueOf(&cfg).Elem().FieldByName(tc.field)
+
+			err := setFieldValue(field, value, tc.value)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, value.Interface())
+		})
+	}
+}
+
+func TestSecretResolveTimeout(t *testing.T) {
+	timeout := SecretResolveTimeout
+	SecretResolveTimeout = 10 * time.Millisecond
+	defer func() { SecretResolveTimeout = timeout }()
+
+	var token string
+	field := reflect.StructField{Name: "Token", Type: reflect.TypeOf(token), Tag: `resolve:"true"`}
+
+	err := setFieldValue(field, reflect.ValueOf(&token).Elem(), "cmd://sleep 1")
+	assert.ErrorIs(t, err, ErrSecretResolve)
+	assert.Empty(t, token)
+}
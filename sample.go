@@ -0,0 +1,149 @@
+package mageconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// GenerateSample returns a sample configuration for the configuration struct in the given format, to
+// document the available parameters. Each field with a 'file' tag (or an 'env' tag for FormatDotenv) is
+// preceded by comments with its description, type, default value and constraints, and set to its default
+// value. The lines of optional fields are commented out. Secret fields are left empty.
+//
+// Since JSON has no comments, FormatJSON returns an object with the default values of all fields.
+func GenerateSample(cfg Config, format Format) ([]byte, error) {
+	cfgType := reflect.TypeOf(cfg)
+	if cfgType == nil || cfgType.Kind() != reflect.Pointer || cfgType.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("config must be a pointer to a struct")
+	}
+	cfgType = cfgType.Elem()
+
+	tag := tagFile
+	if format == FormatDotenv {
+		tag = tagEnv
+	}
+
+	var values []keyValue
+	var b bytes.Buffer
+	for i := 0; i < cfgType.NumField(); i++ {
+		field := cfgType.Field(i)
+		key := field.Tag.Get(tag)
+		if !isLoadable(field) || key == "" {
+			continue
+		}
+
+		value, err := sampleValue(field)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		// The default value as written in the 'default' tag.
+		defaultValue := field.Tag.Get(tagDefault)
+		if isSecret(field) {
+			defaultValue = ""
+		}
+
+		var line string
+		switch format {
+		case FormatNative:
+			line = nativeLine(key, defaultValue) + "\n"
+		case FormatDotenv:
+			line = dotenvLine(key, defaultValue) + "\n"
+		case FormatYAML:
+			encoded, err := encodeYAML([]keyValue{{key: key, value: value}})
+			if err != nil {
+				return nil, err
+			}
+			line = string(encoded)
+		case FormatJSON:
+			values = append(values, keyValue{key: key, value: value})
+			continue
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+		}
+
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		for _, comment := range sampleComments(field) {
+			b.WriteString(strings.TrimRight("# "+comment, " ") + "\n")
+		}
+		// Comment out the lines of optional fields.
+		if field.Tag.Get(tagRequired) != "true" {
+			line = "# " + strings.ReplaceAll(strings.TrimSuffix(line, "\n"), "\n", "\n# ") + "\n"
+		}
+		b.WriteString(line)
+	}
+
+	if format == FormatJSON {
+		return encodeJSON(values)
+	}
+
+	return b.Bytes(), nil
+}
+
+// sampleValue returns the default value of the field for a sample configuration: the parsed 'default' tag,
+// the tag itself if it is computed or a secret reference, or the zero value if there is no default value
+// or the field is secret.
+func sampleValue(field reflect.StructField) (reflect.Value, error) {
+	defaultValue := field.Tag.Get(tagDefault)
+	switch {
+	case defaultValue == "" || isSecret(field):
+		return reflect.Zero(field.Type), nil
+	case strings.Contains(defaultValue, "$") || isSecretReference(field, defaultValue):
+		return reflect.ValueOf(defaultValue), nil
+	}
+
+	value := reflect.New(field.Type).Elem()
+	if err := setFieldByKind(field, value, defaultValue); err != nil {
+		return reflect.Value{}, err
+	}
+
+	return value, nil
+}
+
+// sampleComments returns the comments that document the field in a sample configuration.
+func sampleComments(field reflect.StructField) []string {
+	var comments []string
+	if desc := field.Tag.Get(tagDesc); desc != "" {
+		comments = append(comments, desc)
+	}
+	comments = append(comments, "Type: "+describeType(field.Type))
+
+	if defaultValue := field.Tag.Get(tagDefault); defaultValue != "" {
+		if isSecret(field) {
+			defaultValue = secretMask
+		}
+		comments = append(comments, "Default: "+defaultValue)
+	}
+	if enumStr := field.Tag.Get(tagEnum); enumStr != "" {
+		comments = append(comments, "Allowed values: "+strings.ReplaceAll(enumStr, ",", ", "))
+	}
+	if rangeStr := describeRange(field); rangeStr != "" {
+		comments = append(comments, "Range: "+rangeStr)
+	}
+	if field.Tag.Get(tagRequired) == "true" {
+		comments = append(comments, "Required.")
+	}
+	if isSecret(field) {
+		comments = append(comments, "Secret.")
+	}
+
+	notes := []struct{ tag, label string }{
+		{tagDepends, "Depends on"},
+		{tagConflicts, "Conflicts with"},
+		{tagRequiredWith, "Required with"},
+		{tagRequiredIf, "Required if"},
+		{tagOneOfRequired, "One of required"},
+	}
+	for _, note := range notes {
+		if value := field.Tag.Get(note.tag); value != "" {
+			comments = append(comments, note.label+": "+strings.ReplaceAll(value, ",", ", "))
+		}
+	}
+
+	return comments
+}
@@ -0,0 +1,144 @@
+package mageconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sampleTestConfig struct {
+	URL       string        `file:"url" env:"URL" required:"true" desc:"Database URL"`
+	BackupURL string        `file:"backupURL" env:"BACKUP_URL" default:"${field:URL}/backup" depends:"URL"`
+	Retries   int           `file:"retries" env:"RETRIES" default:"3" desc:"Maximum number of retries"`
+	Tags      []string      `file:"tags" default:"a,b"`
+	APIKey    string        `file:"apiKey" env:"API_KEY" default:"abc" secret:"true"`
+	Timeout   time.Duration `env:"TIMEOUT" default:"5s"`
+	Debug     bool          `arg:"debug"`
+}
+
+func TestGenerateSample(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   Format
+		expected string
+	}{
+		{
+			name:   "Native",
+			format: FormatNative,
+			expected: "" +
+				"# Database URL\n" +
+				"# Type: String\n" +
+				"# Required.\n" +
+				"url:\n" +
+				"\n" +
+				"# Type: String\n" +
+				"# Default: ${field:URL}/backup\n" +
+				"# Depends on: URL\n" +
+				"# backupURL: ${field:URL}/backup\n" +
+				"\n" +
+				"# Maximum number of retries\n" +
+				"# Type: Integer\n" +
+				"# Default: 3\n" +
+				"# retries: 3\n" +
+				"\n" +
+				"# Type: List\n" +
+				"# Default: a,b\n" +
+				"# tags: a,b\n" +
+				"\n" +
+				"# Type: String\n" +
+				"# Default: ******\n" +
+				"# Secret.\n" +
+				"# apiKey:\n",
+		},
+		{
+			name:   "YAML",
+			format: FormatYAML,
+			expected: "" +
+				"# Database URL\n" +
+				"# Type: String\n" +
+				"# Required.\n" +
+				"url: \"\"\n" +
+				"\n" +
+				"# Type: String\n" +
+				"# Default: ${field:URL}/backup\n" +
+				"# Depends on: URL\n" +
+				"# backupURL: ${field:URL}/backup\n" +
+				"\n" +
+				"# Maximum number of retries\n" +
+				"# Type: Integer\n" +
+				"# Default: 3\n" +
+				"# retries: 3\n" +
+				"\n" +
+				"# Type: List\n" +
+				"# Default: a,b\n" +
+				"# tags:\n" +
+				"#     - a\n" +
+				"#     - b\n" +
+				"\n" +
+				"# Type: String\n" +
+				"# Default: ******\n" +
+				"# Secret.\n" +
+				"# apiKey: \"\"\n",
+		},
+		{
+			name:   "JSON",
+			format: FormatJSON,
+			expected: "" +
+				"{\n" +
+				"  \"url\": \"\",\n" +
+				"  \"backupURL\": \"${field:URL}/backup\",\n" +
+				"  \"retries\": 3,\n" +
+				"  \"tags\": [\n" +
+				"    \"a\",\n" +
+				"    \"b\"\n" +
+				"  ],\n" +
+				"  \"apiKey\": \"\"\n" +
+				"}\n",
+		},
+		{
+			name:   "Dotenv",
+			format: FormatDotenv,
+			expected: "" +
+				"# Database URL\n" +
+				"# Type: String\n" +
+				"# Required.\n" +
+				"URL=\n" +
+				"\n" +
+				"# Type: String\n" +
+				"# Default: ${field:URL}/backup\n" +
+				"# Depends on: URL\n" +
+				"# BACKUP_URL=\"${field:URL}/backup\"\n" +
+				"\n" +
+				"# Maximum number of retries\n" +
+				"# Type: Integer\n" +
+				"# Default: 3\n" +
+				"# RETRIES=3\n" +
+				"\n" +
+				"# Type: String\n" +
+				"# Default: ******\n" +
+				"# Secret.\n" +
+				"# API_KEY=\n" +
+				"\n" +
+				"# Type: Duration\n" +
+				"# Default: 5s\n" +
+				"# TIMEOUT=5s\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sample, err := GenerateSample(&sampleTestConfig{}, tt.format)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(sample))
+		})
+	}
+}
+
+func TestGenerateSampleErrors(t *testing.T) {
+	_, err := GenerateSample(&sampleTestConfig{}, "toml")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+
+	_, err = GenerateSample(sampleTestConfig{}, FormatNative)
+	assert.EqualError(t, err, "config must be a pointer to a struct")
+}
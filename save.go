@@ -0,0 +1,224 @@
+package mageconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// ErrUnknownKey is the error returned when no configuration field has the given configuration file key.
+var ErrUnknownKey = errors.New("unknown configuration key")
+
+// SaveOption configures how Save writes the configuration.
+type SaveOption func(*saveOptions)
+
+// saveOptions holds the settings of a single Save call.
+type saveOptions struct {
+	secrets bool
+	keys    []string
+}
+
+// WithSecrets makes Save write the secret fields too, which are omitted by default.
+func WithSecrets() SaveOption {
+	return func(o *saveOptions) {
+		o.secrets = true
+	}
+}
+
+// WithKeys restricts Save to the fields with the given configuration file keys. These fields are written
+// even if their values are zero, and other keys of an existing native-format file are kept unchanged.
+func WithKeys(keys ...string) SaveOption {
+	return func(o *saveOptions) {
+		o.keys = keys
+	}
+}
+
+// Save writes the fields of the configuration with a 'file' tag to the file at path, in the given format,
+// by the names of their 'file' tags and in the order of the struct. Fields with zero values, and secret
+// fields, are omitted unless requested with the WithKeys and WithSecrets options. The effective values
+// are written, so saving a loaded configuration also persists the values set by environment variables
+// and command-line arguments. With the WithSecrets option, the file is made readable by its owner only.
+//
+// An existing file in the native format is updated: the values of the saved keys are replaced in place,
+// new keys are added at the end of the global values, and comments, sections and other keys are kept.
+// Values containing references such as "${HOST}" are kept too, unless their keys are requested with the
+// WithKeys option. In the native format, each "$" is escaped as "$$", so that the values are read back
+// as they are. Files in the other formats are overwritten.
+func Save(cfg Config, path string, format Format, opts ...SaveOption) error {
+	cfgValue := reflect.ValueOf(cfg)
+	if cfgValue.Kind() != reflect.Pointer || cfgValue.Elem().Kind() != reflect.Struct {
+		return errors.New("config must be a pointer to a struct")
+	}
+
+	so := &saveOptions{}
+	for _, opt := range opts {
+		opt(so)
+	}
+
+	values, err := saveValues(Snapshot(cfg), so)
+	if err != nil {
+		return err
+	}
+
+	var content []byte
+	switch format {
+	case FormatNative:
+		existing, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		content = updateNative(existing, values, so.keys == nil)
+	case FormatJSON:
+		content, err = encodeJSON(values)
+	case FormatYAML:
+		content, err = encodeYAML(values)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Keep the file private if it may contain secrets. The permissions of an existing file are not
+	// changed by os.WriteFile, so they are set explicitly.
+	perm := os.FileMode(0o644)
+	if so.secrets {
+		perm = 0o600
+	}
+	if err := os.WriteFile(path, content, perm); err != nil {
+		return err
+	}
+	if so.secrets {
+		return os.Chmod(path, perm)
+	}
+
+	return nil
+}
+
+// saveValues returns the values of the fields of the configuration to save, by their configuration
+// file keys.
+func saveValues(cfg Config, so *saveOptions) ([]keyValue, error) {
+	var values []keyValue
+	found := make(map[string]bool)
+	err := setFields(cfg, func(field reflect.StructField, value reflect.Value) error {
+		key := field.Tag.Get(tagFile)
+		if key == "" {
+			return nil
+		}
+
+		if so.keys != nil {
+			if !contains(so.keys, key) {
+				return nil
+			}
+			found[key] = true
+		} else if value.IsZero() {
+			return nil
+		}
+
+		if isSecret(field) && !so.secrets {
+			return nil
+		}
+
+		values = append(values, keyValue{key: key, value: value})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range so.keys {
+		if !found[key] {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownKey, key)
+		}
+	}
+
+	return values, nil
+}
+
+// updateNative updates the content of a file in the native format with the values. The lines of the
+// global values with the keys of the values are replaced, unless keepRefs is set and their values contain
+// references, and the values without such lines are added after the last global value. Other lines,
+// including comments and sections, are kept.
+func updateNative(content []byte, values []keyValue, keepRefs bool) []byte {
+	var lines []string
+	if len(content) > 0 {
+		lines = strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	}
+
+	// The global values come before the first section header.
+	globalEnd := len(lines)
+	for i, line := range lines {
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			globalEnd = i
+			break
+		}
+	}
+
+	replaced := make(map[string]bool)
+	for i, line := range lines[:globalEnd] {
+		parts := strings.SplitN(line, kvSeparator, 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(parts[0])
+		for _, kv := range values {
+			if kv.key != key {
+				continue
+			}
+			if !keepRefs || !strings.Contains(parts[1], refStart) {
+				lines[i] = nativeLine(kv.key, escapeReferences(formatFieldValue(kv.value)))
+			}
+			replaced[key] = true
+		}
+	}
+
+	// Add the new values after the last non-blank line of the global values.
+	insertAt := globalEnd
+	for insertAt > 0 && strings.TrimSpace(lines[insertAt-1]) == "" {
+		insertAt--
+	}
+	var added []string
+	for _, kv := range values {
+		if !replaced[kv.key] {
+			added = append(added, nativeLine(kv.key, escapeReferences(formatFieldValue(kv.value))))
+		}
+	}
+	lines = append(lines[:insertAt], append(added, lines[insertAt:]...)...)
+
+	if len(lines) == 0 {
+		return nil
+	}
+
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+// SetValue sets the field of the configuration with the given configuration file key to the value,
+// parsed into the type of the field. References such as "${HOST}" are not expanded, and Save escapes
+// them, so the value is read back as it is. Together with Save and the WithKeys option, it allows
+// targets such as "mage config:set key value" to update a single key of the configuration file.
+func SetValue(cfg Config, key, value string) error {
+	cfgValue := reflect.ValueOf(cfg)
+	if cfgValue.Kind() != reflect.Pointer || cfgValue.Elem().Kind() != reflect.Struct {
+		return errors.New("config must be a pointer to a struct")
+	}
+
+	found := false
+	err := setFields(cfg, func(field reflect.StructField, fieldValue reflect.Value) error {
+		if found || field.Tag.Get(tagFile) != key {
+			return nil
+		}
+		found = true
+
+		return setFieldByKind(field, fieldValue, value)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", ErrUnknownKey, key)
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package mageconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type saveTestConfig struct {
+	URL     string            `file:"url"`
+	Retries int               `file:"retries"`
+	Timeout time.Duration     `file:"timeout"`
+	Tags    []string          `file:"tags"`
+	Labels  map[string]string `file:"labels"`
+	Padded  string            `file:"padded"`
+	APIKey  string            `file:"apiKey" secret:"true"`
+	Debug   bool              `arg:"debug"`
+}
+
+func TestSave(t *testing.T) {
+	cfg := saveTestConfig{
+		URL:     "https://example.com",
+		Timeout: 5 * time.Second,
+		Tags:    []string{"a", "b"},
+		Labels:  map[string]string{"team": "core", "env": "dev"},
+		Padded:  " value ",
+		APIKey:  "abc123",
+		Debug:   true,
+	}
+
+	tests := []struct {
+		name     string
+		format   Format
+		opts     []SaveOption
+		expected string
+		wantErr  error
+	}{
+		{
+			name:   "Native",
+			format: FormatNative,
+			expected: "" +
+				"url: https://example.com\n" +
+				"timeout: 5s\n" +
+				"tags: a,b\n" +
+				"labels: env:dev,team:core\n" +
+				"padded: \" value \"\n",
+		},
+		{
+			name:   "Native with secrets",
+			format: FormatNative,
+			opts:   []SaveOption{WithSecrets(), WithKeys("retries", "apiKey")},
+			expected: "" +
+				"retries: 0\n" +
+				"apiKey: abc123\n",
+		},
+		{
+			name:   "JSON",
+			format: FormatJSON,
+			expected: "" +
+				"{\n" +
+				"  \"url\": \"https://example.com\",\n" +
+				"  \"timeout\": \"5s\",\n" +
+				"  \"tags\": [\n" +
+				"    \"a\",\n" +
+				"    \"b\"\n" +
+				"  ],\n" +
+				"  \"labels\": {\n" +
+				"    \"env\": \"dev\",\n" +
+				"    \"team\": \"core\"\n" +
+				"  },\n" +
+				"  \"padded\": \" value \"\n" +
+				"}\n",
+		},
+		{
+			name:   "YAML",
+			format: FormatYAML,
+			expected: "" +
+				"url: https://example.com\n" +
+				"timeout: 5s\n" +
+				"tags:\n" +
+				"    - a\n" +
+				"    - b\n" +
+				"labels:\n" +
+				"    env: dev\n" +
+				"    team: core\n" +
+				"padded: ' value '\n",
+		},
+		{
+			name:    "Unknown key",
+			format:  FormatNative,
+			opts:    []SaveOption{WithKeys("debug")},
+			wantErr: ErrUnknownKey,
+		},
+		{
+			name:    "Unsupported format",
+			format:  "toml",
+			wantErr: ErrUnsupportedFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "mage.config")
+			err := Save(&cfg, path, tt.format, tt.opts...)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+
+			content, err := os.ReadFile(path)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(content))
+		})
+	}
+}
+
+func TestSaveUpdatesNativeFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mage.config")
+	assert.NoError(t, os.WriteFile(path, []byte(""+
+		"# Project settings\n"+
+		"url: https://example.com\n"+
+		"other: kept\n"+
+		"\n"+
+		"[deploy]\n"+
+		"retries: 9\n"), 0o644))
+
+	cfg := saveTestConfig{URL: "https://example.org", Retries: 3}
+	assert.NoError(t, Save(&cfg, path, FormatNative, WithKeys("url", "retries")))
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, ""+
+		"# Project settings\n"+
+		"url: https://example.org\n"+
+		"other: kept\n"+
+		"retries: 3\n"+
+		"\n"+
+		"[deploy]\n"+
+		"retries: 9\n", string(content))
+
+	// The saved file is read back into the same values.
+	sections, err := readConfigFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.org", sections[""]["url"].value)
+	assert.Equal(t, "3", sections[""]["retries"].value)
+}
+
+func TestSaveKeepsReferences(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mage.config")
+	assert.NoError(t, os.WriteFile(path, []byte(""+
+		"url: https://${HOST}/api\n"+
+		"retries: 1\n"), 0o644))
+
+	// The loaded values of references are not saved, unless their keys are requested.
+	cfg := saveTestConfig{URL: "https://example.com/api", Retries: 3, Tags: []string{"a$b"}}
+	assert.NoError(t, Save(&cfg, path, FormatNative))
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, ""+
+		"url: https://${HOST}/api\n"+
+		"retries: 3\n"+
+		"tags: a$$b\n", string(content))
+
+	assert.NoError(t, SetValue(&cfg, "url", "https://$$example.com"))
+	assert.NoError(t, Save(&cfg, path, FormatNative, WithKeys("url")))
+	content, err = os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, ""+
+		"url: https://$$$$example.com\n"+
+		"retries: 3\n"+
+		"tags: a$$b\n", string(content))
+}
+
+func TestSaveWithSecretsPermissions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mage.config")
+	assert.NoError(t, os.WriteFile(path, []byte("url: https://example.com\n"), 0o644))
+
+	cfg := saveTestConfig{APIKey: "abc123"}
+	assert.NoError(t, Save(&cfg, path, FormatNative, WithKeys("apiKey"), WithSecrets()))
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestSetValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		value    string
+		expected saveTestConfig
+		wantErr  string
+	}{
+		{"String", "url", "https://example.com", saveTestConfig{URL: "https://example.com"}, ""},
+		{"Duration", "timeout", "1m", saveTestConfig{Timeout: time.Minute}, ""},
+		{"Map", "labels", "team:core", saveTestConfig{Labels: map[string]string{"team": "core"}}, ""},
+		{"Invalid value", "retries", "many", saveTestConfig{}, `retries: parse field: strconv.ParseInt: parsing "many": invalid syntax`},
+		{"Secret", "apiKey", "abc", saveTestConfig{APIKey: "abc"}, ""},
+		{"Unknown key", "debug", "true", saveTestConfig{}, "unknown configuration key: debug"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := saveTestConfig{}
+			err := SetValue(&cfg, tt.key, tt.value)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, cfg)
+		})
+	}
+}
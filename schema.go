@@ -0,0 +1,238 @@
+package mageconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// jsonSchemaDialect is the JSON Schema dialect of the generated schemas.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// durationPattern matches the durations parsed by time.ParseDuration, such as "1h30m".
+const durationPattern = `^[-+]?(0|([0-9]*(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+)$`
+
+// jsonSchema is a JSON Schema, limited to the keywords used to describe configuration structs.
+type jsonSchema struct {
+	Schema               string              `json:"$schema,omitempty"`
+	Title                string              `json:"title,omitempty"`
+	Description          string              `json:"description,omitempty"`
+	Type                 string              `json:"type,omitempty"`
+	Format               string              `json:"format,omitempty"`
+	Pattern              string              `json:"pattern,omitempty"`
+	Enum                 []interface{}       `json:"enum,omitempty"`
+	Minimum              interface{}         `json:"minimum,omitempty"`
+	Maximum              interface{}         `json:"maximum,omitempty"`
+	Default              interface{}         `json:"default,omitempty"`
+	WriteOnly            bool                `json:"writeOnly,omitempty"`
+	Items                *jsonSchema         `json:"items,omitempty"`
+	AdditionalProperties *jsonSchema         `json:"additionalProperties,omitempty"`
+	Properties           schemaProperties    `json:"properties,omitempty"`
+	Required             []string            `json:"required,omitempty"`
+	DependentRequired    map[string][]string `json:"dependentRequired,omitempty"`
+}
+
+// schemaProperty is a property of an object schema.
+type schemaProperty struct {
+	name   string
+	schema *jsonSchema
+}
+
+// schemaProperties are the properties of an object schema, encoded in the order of the struct fields.
+type schemaProperties []schemaProperty
+
+// MarshalJSON encodes the properties as a JSON object, keeping their order.
+func (p schemaProperties) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, prop := range p {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		name, err := json.Marshal(prop.name)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := json.Marshal(prop.schema)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(name)
+		b.WriteByte(':')
+		b.Write(schema)
+	}
+	b.WriteByte('}')
+
+	return b.Bytes(), nil
+}
+
+// JSONSchema returns a JSON Schema (draft 2020-12) of the configuration files for the configuration struct,
+// so that editors can validate and complete YAML and JSON configuration files. The properties are the
+// fields with a 'file' tag, by its name, described by their 'desc' tags, and with their types, default
+// values, allowed values and ranges from the 'enum', 'min' and 'max' tags. Required fields are listed as
+// required, and the 'depends' tags are expressed as dependentRequired. Nested structs are omitted, as
+// they are not read from the configuration file. Secret fields are marked as write-only, without their
+// default values.
+func JSONSchema(cfg Config) ([]byte, error) {
+	cfgType := reflect.TypeOf(cfg)
+	if cfgType == nil || cfgType.Kind() != reflect.Pointer || cfgType.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("config must be a pointer to a struct")
+	}
+
+	schema, err := objectSchema(cfgType.Elem())
+	if err != nil {
+		return nil, err
+	}
+	schema.Schema = jsonSchemaDialect
+	schema.Title = cfgType.Elem().Name()
+
+	content, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(content, '\n'), nil
+}
+
+// objectSchema returns the schema of the configuration struct type.
+func objectSchema(structType reflect.Type) (*jsonSchema, error) {
+	schema := &jsonSchema{Type: "object"}
+
+	// The names of the properties, by field name, to express the dependencies between fields.
+	propNames := make(map[string]string)
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		// Nested structs are not read from the configuration file.
+		name := field.Tag.Get(tagFile)
+		if !isLoadable(field) || name == "" {
+			continue
+		}
+		prop, err := fieldSchema(field)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		propNames[field.Name] = name
+		schema.Properties = append(schema.Properties, schemaProperty{name: name, schema: prop})
+		if field.Tag.Get(tagRequired) == "true" {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		dependsStr := field.Tag.Get(tagDepends)
+		name, ok := propNames[field.Name]
+		if dependsStr == "" || !ok {
+			continue
+		}
+
+		var depends []string
+		for _, depend := range strings.Split(dependsStr, ",") {
+			if dependName, ok := propNames[depend]; ok {
+				depends = append(depends, dependName)
+			}
+		}
+		if len(depends) > 0 {
+			if schema.DependentRequired == nil {
+				schema.DependentRequired = make(map[string][]string)
+			}
+			schema.DependentRequired[name] = depends
+		}
+	}
+
+	return schema, nil
+}
+
+// fieldSchema returns the schema of a loadable field.
+func fieldSchema(field reflect.StructField) (*jsonSchema, error) {
+	schema := typeSchema(field.Type)
+	schema.Description = field.Tag.Get(tagDesc)
+
+	// The allowed values and ranges apply to the elements of slices and maps.
+	elemSchema := schema
+	switch {
+	case schema.Items != nil:
+		elemSchema = schema.Items
+	case schema.AdditionalProperties != nil:
+		elemSchema = schema.AdditionalProperties
+	}
+
+	if enumStr := field.Tag.Get(tagEnum); enumStr != "" {
+		for _, s := range strings.Split(enumStr, sliceSeparator) {
+			v, err := parseStringToType(strings.TrimSpace(s), elemType(field.Type))
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s value %q: %w", tagEnum, s, err)
+			}
+			elemSchema.Enum = append(elemSchema.Enum, fieldData(v))
+		}
+	}
+
+	// The ranges of durations cannot be expressed, since they are strings.
+	if elemSchema.Type == "integer" || elemSchema.Type == "number" {
+		for _, bound := range []struct {
+			tag   string
+			value *interface{}
+		}{{tagMin, &elemSchema.Minimum}, {tagMax, &elemSchema.Maximum}} {
+			if s := field.Tag.Get(bound.tag); s != "" {
+				v, err := parseStringToType(s, elemType(field.Type))
+				if err != nil {
+					return nil, fmt.Errorf("invalid %s value %q: %w", bound.tag, s, err)
+				}
+				*bound.value = fieldData(v)
+			}
+		}
+	}
+
+	if isSecret(field) {
+		schema.WriteOnly = true
+		return schema, nil
+	}
+
+	// Computed default values and secret references cannot be expressed.
+	defaultValue := field.Tag.Get(tagDefault)
+	if defaultValue != "" && !strings.Contains(defaultValue, "$") && !isSecretReference(field, defaultValue) {
+		value := reflect.New(field.Type).Elem()
+		if err := setFieldByKind(field, value, defaultValue); err != nil {
+			return nil, fmt.Errorf("invalid default value: %w", err)
+		}
+		schema.Default = fieldData(value)
+	}
+
+	return schema, nil
+}
+
+// typeSchema returns the schema of a supported field type.
+func typeSchema(t reflect.Type) *jsonSchema {
+	switch t {
+	case reflect.TypeOf(time.Duration(0)):
+		return &jsonSchema{Type: "string", Pattern: durationPattern}
+	case reflect.TypeOf(time.Time{}):
+		return &jsonSchema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return &jsonSchema{Type: "integer"}
+	case reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &jsonSchema{Type: "integer", Minimum: 0}
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: "number"}
+	case reflect.Slice:
+		return &jsonSchema{Type: "array", Items: typeSchema(t.Elem())}
+	case reflect.Map:
+		return &jsonSchema{Type: "object", AdditionalProperties: typeSchema(t.Elem())}
+	}
+
+	return &jsonSchema{Type: "string"}
+}
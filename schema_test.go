@@ -0,0 +1,98 @@
+package mageconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONSchema(t *testing.T) {
+	type tlsConfig struct {
+		Cert string `file:"cert" desc:"Certificate file"`
+		Key  string `file:"key" depends:"Cert"`
+	}
+	type testConfig struct {
+		URL       string             `file:"url" required:"true" desc:"Database URL"`
+		BackupURL string             `file:"backupURL" default:"${field:URL}/backup" depends:"URL"`
+		Mode      string             `file:"mode" enum:"dev,prod" default:"dev"`
+		Retries   int                `file:"retries" min:"0" max:"10" default:"3"`
+		Ports     []uint             `file:"ports" max:"65535"`
+		Timeout   time.Duration      `file:"timeout" default:"5s"`
+		Start     time.Time          `file:"start"`
+		Limits    map[string]float64 `file:"limits"`
+		APIKey    string             `file:"apiKey" secret:"true" default:"abc"`
+		Debug     bool               `arg:"debug"`
+		TLS       tlsConfig          `desc:"TLS settings"` // Not read from the configuration file.
+	}
+
+	schema, err := JSONSchema(&testConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "testConfig",
+  "type": "object",
+  "properties": {
+    "url": {
+      "description": "Database URL",
+      "type": "string"
+    },
+    "backupURL": {
+      "type": "string"
+    },
+    "mode": {
+      "type": "string",
+      "enum": [
+        "dev",
+        "prod"
+      ],
+      "default": "dev"
+    },
+    "retries": {
+      "type": "integer",
+      "minimum": 0,
+      "maximum": 10,
+      "default": 3
+    },
+    "ports": {
+      "type": "array",
+      "items": {
+        "type": "integer",
+        "minimum": 0,
+        "maximum": 65535
+      }
+    },
+    "timeout": {
+      "type": "string",
+      "pattern": "^[-+]?(0|([0-9]*(\\.[0-9]*)?(ns|us|µs|ms|s|m|h))+)$",
+      "default": "5s"
+    },
+    "start": {
+      "type": "string",
+      "format": "date-time"
+    },
+    "limits": {
+      "type": "object",
+      "additionalProperties": {
+        "type": "number"
+      }
+    },
+    "apiKey": {
+      "type": "string",
+      "writeOnly": true
+    }
+  },
+  "required": [
+    "url"
+  ],
+  "dependentRequired": {
+    "backupURL": [
+      "url"
+    ]
+  }
+}
+`, string(schema))
+
+	_, err = JSONSchema(testConfig{})
+	assert.EqualError(t, err, "config must be a pointer to a struct")
+}
@@ -0,0 +1,83 @@
+package mageconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Files and directories marking the root of a project, looked for when searching the configuration file.
+var projectRootMarkers = []string{"magefile.go", "go.mod", ".git"}
+
+// findConfigFile searches for the configuration file with a relative path in the following locations,
+// and returns the first existing one: the current working directory, the root of the project (the
+// nearest parent directory containing magefile.go, go.mod or .git), the user configuration directory
+// ($XDG_CONFIG_HOME/mage, ~/.config/mage by default), and the system configuration directories
+// ($XDG_CONFIG_DIRS/mage, /etc/xdg/mage by default). The file is returned unchanged if it is absolute,
+// or if it is not found.
+func findConfigFile(file string) string {
+	if file == "" || filepath.IsAbs(file) {
+		return file
+	}
+
+	for _, dir := range configSearchDirs() {
+		path := filepath.Join(dir, file)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+
+	return file
+}
+
+// configSearchDirs returns the directories to search for the configuration file, in order.
+func configSearchDirs() []string {
+	var dirs []string
+
+	if wd, err := os.Getwd(); err == nil {
+		dirs = append(dirs, wd)
+		if root := findProjectRoot(wd); root != "" && root != wd {
+			dirs = append(dirs, root)
+		}
+	}
+
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			configHome = filepath.Join(home, ".config")
+		}
+	}
+	if configHome != "" {
+		dirs = append(dirs, filepath.Join(configHome, "mage"))
+	}
+
+	configDirs := os.Getenv("XDG_CONFIG_DIRS")
+	if configDirs == "" {
+		configDirs = "/etc/xdg"
+	}
+	for _, dir := range strings.Split(configDirs, string(os.PathListSeparator)) {
+		if dir != "" {
+			dirs = append(dirs, filepath.Join(dir, "mage"))
+		}
+	}
+
+	return dirs
+}
+
+// findProjectRoot returns the nearest directory, starting from dir and walking up, that contains one
+// of the project root markers, or an empty string if there is none.
+func findProjectRoot(dir string) string {
+	for {
+		for _, marker := range projectRootMarkers {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+				return dir
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,110 @@
+package mageconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindConfigFile(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "a", "b")
+	configHome := filepath.Join(root, "home")
+	configDir := filepath.Join(root, "etc")
+	assert.NoError(t, os.MkdirAll(sub, 0o755))
+	assert.NoError(t, os.MkdirAll(filepath.Join(configHome, "mage"), 0o755))
+	assert.NoError(t, os.MkdirAll(filepath.Join(configDir, "mage"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example\n"), 0o644))
+
+	write := func(path string) {
+		assert.NoError(t, os.WriteFile(path, []byte("url: test\n"), 0o644))
+	}
+	write(filepath.Join(root, "root.config"))
+	write(filepath.Join(sub, "local.config"))
+	write(filepath.Join(root, "local.config"))
+	write(filepath.Join(configHome, "mage", "user.config"))
+	write(filepath.Join(configDir, "mage", "user.config"))
+	write(filepath.Join(configDir, "mage", "system.config"))
+
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("XDG_CONFIG_DIRS", configDir)
+
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, os.Chdir(wd)) }()
+	assert.NoError(t, os.Chdir(sub))
+
+	// Resolve symbolic links in the temporary directory, as the working directory does.
+	realRoot, err := filepath.EvalSymlinks(root)
+	assert.NoError(t, err)
+	realSub := filepath.Join(realRoot, "a", "b")
+
+	tests := []struct {
+		name     string
+		file     string
+		expected string
+	}{
+		{"Current directory", "local.config", filepath.Join(realSub, "local.config")},
+		{"Project root", "root.config", filepath.Join(realRoot, "root.config")},
+		{"User configuration directory", "user.config", filepath.Join(configHome, "mage", "user.config")},
+		{"System configuration directory", "system.config", filepath.Join(configDir, "mage", "system.config")},
+		{"Not found", "missing.config", "missing.config"},
+		{"Absolute path", filepath.Join(root, "missing.config"), filepath.Join(root, "missing.config")},
+		{"Empty path", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, findConfigFile(tt.file))
+		})
+	}
+}
+
+func TestFindProjectRoot(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "a", "b")
+	assert.NoError(t, os.MkdirAll(sub, 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "magefile.go"), nil, 0o644))
+
+	assert.Equal(t, root, findProjectRoot(sub))
+	assert.Equal(t, root, findProjectRoot(root))
+}
+
+func TestLoadWithFileSearch(t *testing.T) {
+	type testConfig struct {
+		URL string `file:"url"`
+	}
+
+	root := t.TempDir()
+	sub := filepath.Join(root, "sub")
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, ".git"), 0o755))
+	assert.NoError(t, os.MkdirAll(sub, 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "mage.config"), []byte("url: https://example.com\n"), 0o644))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "home"))
+	t.Setenv("XDG_CONFIG_DIRS", filepath.Join(root, "etc"))
+
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, os.Chdir(wd)) }()
+	assert.NoError(t, os.Chdir(sub))
+
+	realRoot, err := filepath.EvalSymlinks(root)
+	assert.NoError(t, err)
+
+	// Beware: this modifies global state and is not safe for parallel test execution.
+	os.Args = []string{"cmd"}
+	defer func() { isLoaded = false }()
+
+	cfg := testConfig{}
+	assert.NoError(t, Load(&cfg, "mage.config"))
+	assert.Equal(t, "", cfg.URL)
+	assert.Empty(t, ConfigFiles(&cfg))
+	isLoaded = false
+
+	cfg = testConfig{}
+	assert.NoError(t, Load(&cfg, "mage.config", WithFileSearch()))
+	assert.Equal(t, "https://example.com", cfg.URL)
+	assert.Equal(t, []string{filepath.Join(realRoot, "mage.config")}, ConfigFiles(&cfg))
+}
@@ -0,0 +1,72 @@
+package mageconfig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+const (
+	tagSecret  = "secret" // Specifies whether the parameter is a secret that must not be displayed.
+	secretMask = "******" // The placeholder displayed instead of secret values.
+)
+
+// secretError hides the message of an error caused by a secret value, as it may contain the value itself.
+// The original error is still available with errors.Unwrap.
+type secretError struct {
+	field string
+	err   error
+}
+
+// Error returns the error message without the secret value.
+func (e *secretError) Error() string {
+	return fmt.Sprintf("parse field: invalid value of secret field %s", e.field)
+}
+
+// Unwrap returns the original error.
+func (e *secretError) Unwrap() error {
+	return e.err
+}
+
+// isSecret reports whether the field is marked with the 'secret' tag.
+func isSecret(field reflect.StructField) bool {
+	return field.Tag.Get(tagSecret) == "true"
+}
+
+// Redacted returns a copy of the configuration that is safe to log. Secret string fields are replaced
+// with a mask (unless empty) and the other secret fields are reset to their zero values. Structs nested
+// in the configuration are redacted as well.
+func Redacted(cfg Config) Config {
+	cfgValue := reflect.ValueOf(cfg)
+	if cfgValue.Kind() != reflect.Pointer || cfgValue.IsNil() || cfgValue.Elem().Kind() != reflect.Struct {
+		return cfg
+	}
+
+	redacted := Snapshot(cfg)
+	redactValue(reflect.ValueOf(redacted).Elem())
+
+	return redacted
+}
+
+// redactValue masks the secret fields of the struct value in place.
+func redactValue(v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		value := v.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if !isSecret(field) {
+			if !isLoadable(field) {
+				redactValue(value) // Nested struct.
+			}
+			continue
+		}
+
+		if value.Kind() == reflect.String && value.Len() > 0 {
+			value.SetString(secretMask)
+			continue
+		}
+		value.Set(reflect.Zero(field.Type))
+	}
+}
@@ -0,0 +1,58 @@
+package mageconfig
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedacted(t *testing.T) {
+	type credentials struct {
+		User     string
+		Password string `secret:"true"`
+	}
+	type testConfig struct {
+		URL         string
+		APIKey      string `secret:"true"`
+		EmptyKey    string `secret:"true"`
+		PIN         int    `secret:"true"`
+		Credentials credentials
+	}
+
+	cfg := &testConfig{
+		URL:         "https://example.com",
+		APIKey:      "abc123",
+		PIN:         1234,
+		Credentials: credentials{User: "admin", Password: "qwerty"},
+	}
+
+	redacted := Redacted(cfg)
+	assert.Equal(t, &testConfig{
+		URL:         "https://example.com",
+		APIKey:      secretMask,
+		Credentials: credentials{User: "admin", Password: secretMask},
+	}, redacted)
+
+	// The original configuration must be left intact.
+	assert.Equal(t, "abc123", cfg.APIKey)
+	assert.Equal(t, "qwerty", cfg.Credentials.Password)
+}
+
+func TestSecretError(t *testing.T) {
+	type testConfig struct {
+		PIN int `secret:"true"`
+	}
+
+	cfg := testConfig{}
+	err := setFields(&cfg, func(field reflect.StructField, value reflect.Value) error {
+		return setFieldByKind(field, value, "12a4")
+	})
+	assert.EqualError(t, err, "parse field: invalid value of secret field PIN")
+	assert.NotContains(t, err.Error(), "12a4")
+
+	var numErr *strconv.NumError
+	assert.True(t, errors.As(err, &numErr))
+}
@@ -0,0 +1,153 @@
+package mageconfig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const tagSources = "sources" // Specifies the comma-separated kinds of sources the parameter accepts.
+
+// Field describes a configuration field looked up in a Source.
+type Field struct {
+	// Name is the name of the field in the configuration struct.
+	Name string
+	// Type is the type of the field.
+	Type reflect.Type
+	// Tag is the tag of the field. Sources usually define their own tag to map fields to their keys.
+	Tag reflect.StructTag
+}
+
+// Value is the raw value of a configuration field found by a Source.
+type Value struct {
+	// Raw is the value as a string, in the format of the 'default' tag. It may contain references
+	// expanded with the values of other sources, and secret references.
+	Raw string
+	// Location is the place of the value within the source, such as "settings.db:12", reported by Origins.
+	Location string
+}
+
+// Source provides the values of configuration fields, such as a configuration file or environment variables.
+// Custom sources, such as a git-config reader or a settings table, can be added with the WithCustomSource
+// option, and take part in precedence, provenance and validation like the built-in ones.
+type Source interface {
+	// Kind returns the kind of the source, used by the WithSources option, the 'sources' tag and Origins.
+	Kind() SourceKind
+	// Lookup returns the raw value of the field and reports whether the source has a value for it.
+	Lookup(field Field) (Value, bool, error)
+}
+
+// SourceChecker is an optional interface that custom sources can implement to verify the definition of
+// the configuration struct, such as the names in their own tag, before any source is read. The error
+// is returned wrapped in ErrInvalidSchema.
+type SourceChecker interface {
+	CheckFields(fields []Field) error
+}
+
+// LookupFunc is the function type of the Lookup method of a Source.
+type LookupFunc func(field Field) (Value, bool, error)
+
+// lookupFuncSource is a Source defined by its kind and lookup function.
+type lookupFuncSource struct {
+	kind   SourceKind
+	lookup LookupFunc
+}
+
+// NewSource returns a Source of the given kind, whose values are looked up with the lookup function.
+func NewSource(kind SourceKind, lookup LookupFunc) Source {
+	return &lookupFuncSource{kind: kind, lookup: lookup}
+}
+
+// Kind returns the kind of the source.
+func (s *lookupFuncSource) Kind() SourceKind {
+	return s.kind
+}
+
+// Lookup calls the lookup function.
+func (s *lookupFuncSource) Lookup(field Field) (Value, bool, error) {
+	return s.lookup(field)
+}
+
+// loadFromSource loads configuration parameters from a source into a configuration struct.
+func loadFromSource(cfg Config, src Source, isSet map[string]*bool, raw map[string]*rawValue) error {
+	kind := src.Kind()
+
+	return setFields(cfg, func(field reflect.StructField, value reflect.Value) error {
+		if !acceptsSource(field, kind) {
+			return nil
+		}
+
+		srcValue, ok, err := src.Lookup(newField(field))
+		if err != nil {
+			return fmt.Errorf("%s source: field %s: %w", kind, field.Name, err)
+		}
+		if !ok {
+			return nil
+		}
+
+		return setRawValue(field, value, srcValue.Raw, Origin{Source: kind, Location: srcValue.Location}, isSet, raw)
+	})
+}
+
+// newField returns the description of a struct field passed to sources.
+func newField(field reflect.StructField) Field {
+	return Field{Name: field.Name, Type: field.Type, Tag: field.Tag}
+}
+
+// acceptsSource reports whether the field accepts values from the kind of source, according to its
+// 'sources' tag. Fields without the tag accept all sources, and default values are always accepted.
+func acceptsSource(field reflect.StructField, kind SourceKind) bool {
+	sourcesStr := field.Tag.Get(tagSources)
+	if sourcesStr == "" || kind == SourceDefault {
+		return true
+	}
+
+	for _, s := range strings.Split(sourcesStr, ",") {
+		if SourceKind(strings.TrimSpace(s)) == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSources verifies that the 'sources' tags of the configuration struct only list known kinds of
+// sources, and lets the sources implementing SourceChecker verify the struct.
+func checkSources(cfg Config, sources []Source) error {
+	known := make([]SourceKind, 0, len(defaultSources)+len(sources))
+	known = append(known, defaultSources...)
+	for _, src := range sources {
+		known = append(known, src.Kind())
+	}
+
+	var errs []error
+	var fields []Field
+	_ = setFields(cfg, func(field reflect.StructField, _ reflect.Value) error {
+		fields = append(fields, newField(field))
+
+		sourcesStr := field.Tag.Get(tagSources)
+		if sourcesStr == "" {
+			return nil
+		}
+		for _, s := range strings.Split(sourcesStr, ",") {
+			if kind := SourceKind(strings.TrimSpace(s)); !containsSource(known, kind) {
+				errs = append(errs, fmt.Errorf("field %s: unknown source %q in %s tag", field.Name, kind, tagSources))
+			}
+		}
+		return nil
+	})
+
+	for _, src := range sources {
+		if checker, ok := src.(SourceChecker); ok {
+			if err := checker.CheckFields(fields); err != nil {
+				errs = append(errs, fmt.Errorf("%s source: %w", src.Kind(), err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidSchema, errors.Join(errs...))
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package mageconfig
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testTableSource is a custom source reading values from a settings table by the 'setting' tag.
+type testTableSource struct {
+	rows map[string]string
+}
+
+func (s *testTableSource) Kind() SourceKind {
+	return "table"
+}
+
+func (s *testTableSource) Lookup(field Field) (Value, bool, error) {
+	key := field.Tag.Get("setting")
+	value, ok := s.rows[key]
+	if key == "" || !ok {
+		return Value{}, false, nil
+	}
+	return Value{Raw: value, Location: "settings." + key}, true, nil
+}
+
+func (s *testTableSource) CheckFields(fields []Field) error {
+	for _, field := range fields {
+		if field.Tag.Get("setting") == "-" {
+			return fmt.Errorf("field %s: invalid setting name", field.Name)
+		}
+	}
+	return nil
+}
+
+func TestLoadFromCustomSource(t *testing.T) {
+	type testConfig struct {
+		Registry string `arg:"registry" setting:"registry" default:"docker.io"`
+		Retries  int    `setting:"retries" sources:"table"`
+		Token    string `setting:"token" required:"true" secret:"true"`
+	}
+
+	table := &testTableSource{rows: map[string]string{"registry": "ghcr.io", "retries": "5", "token": "abc"}}
+
+	// Beware: this modifies global state and is not safe for parallel test execution.
+	os.Args = []string{"cmd", "-registry=quay.io", "-retries=7"}
+	defer func() { isLoaded = false }()
+
+	cfg := testConfig{}
+	err := Load(&cfg, "", WithCustomSource(table), WithSources(SourceDefault, "table", SourceArg))
+	assert.NoError(t, err)
+	assert.Equal(t, testConfig{Registry: "quay.io", Retries: 5, Token: "abc"}, cfg)
+	assert.Equal(t, map[string]Origin{
+		"Registry": {Source: SourceArg, Location: "-registry"},
+		"Retries":  {Source: "table", Location: "settings.retries"},
+		"Token":    {Source: "table", Location: "settings.token"},
+	}, Origins(&cfg))
+}
+
+func TestCheckSources(t *testing.T) {
+	type testConfig struct {
+		Registry string `setting:"-"`
+		Retries  int    `sources:"table,vault"`
+	}
+
+	// Beware: this modifies global state and is not safe for parallel test execution.
+	os.Args = []string{"cmd"}
+	defer func() { isLoaded = false }()
+
+	cfg := testConfig{}
+	err := Load(&cfg, "", WithCustomSource(&testTableSource{}))
+	assert.ErrorIs(t, err, ErrInvalidSchema)
+	assert.EqualError(t, err, "invalid configuration schema: "+
+		"field Retries: unknown source \"vault\" in sources tag\n"+
+		"table source: field Registry: invalid setting name")
+}
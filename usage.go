@@ -1,13 +1,33 @@
 package mageconfig
 
 import (
-	"flag"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"reflect"
+	"strconv"
 	"strings"
+	"text/template"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const (
+	tagGroup         = "group" // Defines the group the parameter is listed under in the help.
+	defaultHelpWidth = 80      // The width of the help when the terminal width is unknown.
 )
 
+// defaultHelpHeader is the template of the header of the help.
+const defaultHelpHeader = `Usage of {{.Program}}
+
+This application is configured via the config file, environment variables, or command-line arguments.
+The following configurations can be used:
+{{if .Profiles}}
+Active profiles: {{join .Profiles ", "}}
+{{end}}`
+
 // isHelpRequested checks if the help flag (-help or --help) was provided in the command-line arguments.
 func isHelpRequested() bool {
 	for _, arg := range os.Args {
@@ -19,70 +39,454 @@ func isHelpRequested() bool {
 	return false
 }
 
-// printUsage prints the usage instructions for the application, including the available configurations,
-// their types, default values, and whether they are required.
-func printUsage(cfgType reflect.Type) {
-	const helpMessage = "This application is configured via the config file," +
-		" environment variables, or command-line arguments.\n" +
-		"The following configurations can be used:\n" +
-		"[CONFIG FILE KEY, ENVIRONMENT VARIABLE, CLI ARGUMENT]"
+// HelpLayout is the layout of the configuration parameters in the help.
+type HelpLayout int
+
+// Layouts of the help.
+const (
+	HelpVerbose HelpLayout = iota // Each parameter with its details on separate, aligned lines.
+	HelpCompact                   // Each parameter on a single row of an aligned table.
+)
+
+// HelpData is the data passed to the header and footer templates of the help.
+type HelpData struct {
+	// Program is the name of the program, from os.Args[0].
+	Program string
+	// Profiles are the active profiles.
+	Profiles []string
+}
+
+// HelpOption configures how the help is rendered.
+type HelpOption func(*helpOptions)
+
+// helpOptions holds the settings of the help.
+type helpOptions struct {
+	layout   HelpLayout
+	width    int
+	header   string
+	footer   string
+	profiles []string
+}
+
+// WithHelpLayout sets the layout of the help. The default layout is HelpVerbose.
+func WithHelpLayout(layout HelpLayout) HelpOption {
+	return func(o *helpOptions) {
+		o.layout = layout
+	}
+}
+
+// WithHelpWidth sets the width descriptions are wrapped to. By default, it is the width of the terminal,
+// or the COLUMNS environment variable if the output is not a terminal, or 80 if unknown. A width of zero
+// or less disables wrapping.
+func WithHelpWidth(width int) HelpOption {
+	return func(o *helpOptions) {
+		o.width = width
+	}
+}
+
+// WithHelpHeader sets the text/template of the header of the help, executed with HelpData. The default
+// header shows the program name and the active profiles.
+func WithHelpHeader(tmpl string) HelpOption {
+	return func(o *helpOptions) {
+		o.header = tmpl
+	}
+}
+
+// WithHelpFooter sets the text/template of the footer of the help, executed with HelpData.
+func WithHelpFooter(tmpl string) HelpOption {
+	return func(o *helpOptions) {
+		o.footer = tmpl
+	}
+}
+
+// newHelpOptions applies the options to the default settings.
+func newHelpOptions(opts []HelpOption) *helpOptions {
+	o := &helpOptions{
+		layout:   HelpVerbose,
+		width:    terminalWidth(),
+		header:   defaultHelpHeader,
+		profiles: detectProfiles(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// terminalWidth returns the width of the terminal of the standard output, or of the standard error if
+// the standard output is redirected. If neither is a terminal, it returns the width from the COLUMNS
+// environment variable, or the default width if it is not set.
+func terminalWidth() int {
+	for _, f := range []*os.File{os.Stdout, os.Stderr} {
+		if width, _, err := term.GetSize(int(f.Fd())); err == nil && width > 0 {
+			return width
+		}
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+
+	return defaultHelpWidth
+}
+
+// helpField describes a configuration parameter in the help.
+type helpField struct {
+	title       string      // The name the parameter is listed by: its argument, or its field name.
+	arg         string      // The command-line argument, if accepted.
+	env         string      // The environment variable, if accepted.
+	file        string      // The configuration file key, if accepted.
+	typeName    string      // The human-readable type.
+	defaultStr  string      // The default value, masked for secrets.
+	description string      // The description.
+	details     [][2]string // The other details, as labels and values.
+}
+
+// helpGroup is a group of configuration parameters in the help.
+type helpGroup struct {
+	name   string
+	fields []helpField
+}
+
+// PrintHelp writes the help of the configuration struct to w: a header, the configuration parameters with
+// their names in each source they are read from, types, default values, constraints and descriptions, and
+// a footer. Parameters with a 'group' tag are listed under the name of their group, after the other
+// parameters. It is written when Load is called with the -help or --help argument, configured with the
+// WithHelp option.
+func PrintHelp(w io.Writer, cfg Config, opts ...HelpOption) error {
+	return printHelp(w, cfg, newHelpOptions(opts))
+}
+
+// printHelp writes the help of the configuration struct to w with the settings.
+func printHelp(w io.Writer, cfg Config, o *helpOptions) error {
+	cfgType := reflect.TypeOf(cfg)
+	if cfgType == nil || cfgType.Kind() != reflect.Pointer || cfgType.Elem().Kind() != reflect.Struct {
+		return errors.New("config must be a pointer to a struct")
+	}
+
+	data := HelpData{Program: os.Args[0], Profiles: o.profiles}
+	if err := executeHelpTemplate(w, "header", o.header, data, "\n\n"); err != nil {
+		return err
+	}
 
-	fmt.Fprintln(flag.CommandLine.Output(), "Usage of", os.Args[0])
-	fmt.Fprintln(flag.CommandLine.Output())
-	fmt.Fprintln(flag.CommandLine.Output(), helpMessage)
-	fmt.Fprintln(flag.CommandLine.Output())
+	groups := helpGroups(cfgType.Elem())
+	var err error
+	switch o.layout {
+	case HelpCompact:
+		err = writeCompactHelp(w, groups, o.width)
+	default:
+		err = writeVerboseHelp(w, groups, o.width)
+	}
+	if err != nil {
+		return err
+	}
 
-	// Iterate over each field in the configuration type and print its details.
+	return executeHelpTemplate(w, "footer", o.footer, data, "\n")
+}
+
+// executeHelpTemplate executes a header or footer template of the help, without its trailing newlines,
+// followed by the suffix unless it is empty.
+func executeHelpTemplate(w io.Writer, name, text string, data HelpData, suffix string) error {
+	if text == "" {
+		return nil
+	}
+
+	tmpl, err := template.New(name).Funcs(template.FuncMap{"join": strings.Join}).Parse(text)
+	if err != nil {
+		return fmt.Errorf("help %s: %w", name, err)
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return fmt.Errorf("help %s: %w", name, err)
+	}
+
+	_, err = io.WriteString(w, strings.TrimRight(b.String(), "\n")+suffix)
+	return err
+}
+
+// helpGroups returns the configuration parameters of the struct type, grouped by their 'group' tags.
+// The parameters without a group come first, then the groups in the order of their first appearance.
+func helpGroups(cfgType reflect.Type) []helpGroup {
+	groups := []helpGroup{{}}
+	index := map[string]int{"": 0}
 	for i := 0; i < cfgType.NumField(); i++ {
 		field := cfgType.Field(i)
+		if !isLoadable(field) {
+			continue
+		}
 
-		// Retrieve the field details from its tags.
-		argName := getTagOrDefault(field, tagArg)
-		envName := field.Tag.Get(tagEnv)
-		fileFieldName := field.Tag.Get(tagFile)
-		defaultValue := field.Tag.Get(tagDefault)
-		description := field.Tag.Get(tagDesc)
-		required := field.Tag.Get(tagRequired)
-		dependsStr := field.Tag.Get(tagDepends)
-
-		// Define a placeholder for unused fields.
-		const notUsedStr = "<NOTUSED>"
-		if envName == "" {
-			envName = notUsedStr
-		}
-		if fileFieldName == "" {
-			fileFieldName = notUsedStr
-		}
-
-		// Determine the type of the field for the help message.
-		typeStr := "String" // default type as string.
-		switch field.Type.Kind() {
-		case reflect.Bool:
-			typeStr = "True or False"
-		case reflect.Int, reflect.Int32, reflect.Int64:
-			typeStr = "Integer"
-		case reflect.Uint, reflect.Uint32, reflect.Uint64:
-			typeStr = "Unsigned Integer"
-		case reflect.Float32, reflect.Float64:
-			typeStr = "Float"
-		case reflect.Slice:
-			typeStr = "List"
-		case reflect.Map:
-			typeStr = "Map"
-		}
-
-		fmt.Fprintf(flag.CommandLine.Output(), "%s, %s, --%s:\n", fileFieldName, envName, argName)
-		fmt.Fprintf(flag.CommandLine.Output(), "    description: %s\n", description)
-		fmt.Fprintf(flag.CommandLine.Output(), "    type:        %s\n", typeStr)
-		if defaultValue != "" {
-			fmt.Fprintf(flag.CommandLine.Output(), "    default:     %s\n", defaultValue)
-		}
-		if required == "true" {
-			fmt.Fprintf(flag.CommandLine.Output(), "    required:    true\n")
-		}
-		if dependsStr != "" {
-			fmt.Fprintf(flag.CommandLine.Output(), "     depends:     %s\n", strings.Split(dependsStr, ","))
-		}
-		fmt.Fprintf(flag.CommandLine.Output(), "\n")
+		name := field.Tag.Get(tagGroup)
+		if _, ok := index[name]; !ok {
+			index[name] = len(groups)
+			groups = append(groups, helpGroup{name: name})
+		}
+		groups[index[name]].fields = append(groups[index[name]].fields, newHelpField(field))
 	}
+
+	return groups
+}
+
+// newHelpField returns the description of the field in the help.
+func newHelpField(field reflect.StructField) helpField {
+	f := helpField{
+		title:       field.Name,
+		typeName:    describeType(field.Type),
+		defaultStr:  field.Tag.Get(tagDefault),
+		description: field.Tag.Get(tagDesc),
+	}
+
+	// Only show the names of the sources the field is read from.
+	if acceptsSource(field, SourceArg) {
+		f.arg = argPrefix + argPrefix + getTagOrDefault(field, tagArg)
+		f.title = f.arg
+	}
+	if name := field.Tag.Get(tagEnv); name != "" && acceptsSource(field, SourceEnv) {
+		f.env = name
+	}
+	if name := field.Tag.Get(tagFile); name != "" && acceptsSource(field, SourceFile) {
+		f.file = name
+	}
+
+	// Hide the default value of secret fields, and mark default values computed from references.
+	if f.defaultStr != "" && isSecret(field) {
+		f.defaultStr = secretMask
+	} else if strings.Contains(f.defaultStr, "$") {
+		f.defaultStr += " (computed)"
+	}
+
+	addDetail := func(label, value string) {
+		if value != "" {
+			f.details = append(f.details, [2]string{label, value})
+		}
+	}
+	list := func(tag string) string {
+		return strings.ReplaceAll(field.Tag.Get(tag), ",", ", ")
+	}
+	if key := field.Tag.Get(tagGit); key != "" && acceptsSource(field, SourceGit) {
+		addDetail("git config", key)
+	}
+	if field.Tag.Get(tagRequired) == "true" {
+		addDetail("required", "true")
+	}
+	addDetail("allowed", list(tagEnum))
+	addDetail("range", describeRange(field))
+	addDetail("sources", list(tagSources))
+	if isSecret(field) {
+		addDetail("secret", "true")
+	}
+	addDetail("depends", list(tagDepends))
+	addDetail("conflicts", list(tagConflicts))
+	addDetail("required if", field.Tag.Get(tagRequiredIf))
+	addDetail("required with", list(tagRequiredWith))
+	addDetail("one of required", field.Tag.Get(tagOneOfRequired))
+
+	return f
+}
+
+// writeVerboseHelp writes each parameter with its description and details on separate lines, with
+// the values of the details aligned.
+func writeVerboseHelp(w io.Writer, groups []helpGroup, width int) error {
+	const (
+		fieldIndent  = "  "
+		detailIndent = "      "
+	)
+
+	// Collect the details of all fields, to align their values.
+	labelWidth := 0
+	fieldDetails := make(map[*helpField][][2]string)
+	for g := range groups {
+		for i := range groups[g].fields {
+			f := &groups[g].fields[i]
+			var details [][2]string
+			if f.file != "" {
+				details = append(details, [2]string{"file", f.file})
+			}
+			if f.env != "" {
+				details = append(details, [2]string{"env", f.env})
+			}
+			details = append(details, [2]string{"type", f.typeName})
+			if f.defaultStr != "" {
+				details = append(details, [2]string{"default", f.defaultStr})
+			}
+			details = append(details, f.details...)
+			for _, d := range details {
+				if len(d[0])+1 > labelWidth {
+					labelWidth = len(d[0]) + 1
+				}
+			}
+			fieldDetails[f] = details
+		}
+	}
+
+	var b strings.Builder
+	for _, group := range groups {
+		if len(group.fields) == 0 {
+			continue
+		}
+		if group.name != "" {
+			b.WriteString(group.name + ":\n")
+		}
+
+		for i := range group.fields {
+			f := &group.fields[i]
+			b.WriteString(fieldIndent + f.title + "\n")
+			for _, line := range wrapText(f.description, width-len(detailIndent)) {
+				b.WriteString(detailIndent + line + "\n")
+			}
+			for _, d := range fieldDetails[f] {
+				fmt.Fprintf(&b, "%s%-*s %s\n", detailIndent, labelWidth, d[0]+":", d[1])
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeCompactHelp writes each parameter on a single row of a table, with aligned columns. The columns
+// of the environment variables and configuration file keys are only written if any parameter uses them,
+// and the descriptions are wrapped to the width.
+func writeCompactHelp(w io.Writer, groups []helpGroup, width int) error {
+	const (
+		indent = "  "
+		gap    = "  "
+	)
+
+	// Determine the columns in use and their widths.
+	columns := []struct {
+		header string
+		value  func(f *helpField) string
+		width  int
+		used   bool
+	}{
+		{header: "ARGUMENT", value: func(f *helpField) string { return f.title }},
+		{header: "ENV", value: func(f *helpField) string { return f.env }},
+		{header: "FILE", value: func(f *helpField) string { return f.file }},
+		{header: "TYPE", value: func(f *helpField) string { return f.typeName }},
+		{header: "DEFAULT", value: func(f *helpField) string { return f.defaultStr }},
+	}
+	for c := range columns {
+		columns[c].width = len(columns[c].header)
+		for g := range groups {
+			for i := range groups[g].fields {
+				if value := columns[c].value(&groups[g].fields[i]); value != "" {
+					if len(value) > columns[c].width {
+						columns[c].width = len(value)
+					}
+					columns[c].used = true
+				}
+			}
+		}
+	}
+
+	var b strings.Builder
+	writeRow := func(cells []string, description string) {
+		row := indent
+		for c, cell := range cells {
+			if !columns[c].used {
+				continue
+			}
+			row += fmt.Sprintf("%-*s", columns[c].width, cell) + gap
+		}
+		lines := wrapText(description, width-len(row))
+		if len(lines) == 0 {
+			b.WriteString(strings.TrimRight(row, " ") + "\n")
+			return
+		}
+		b.WriteString(row + lines[0] + "\n")
+		for _, line := range lines[1:] {
+			b.WriteString(strings.Repeat(" ", len(row)) + line + "\n")
+		}
+	}
+
+	headers := make([]string, len(columns))
+	for c := range columns {
+		headers[c] = columns[c].header
+	}
+	for _, group := range groups {
+		if len(group.fields) == 0 {
+			continue
+		}
+		if group.name != "" {
+			b.WriteString(group.name + ":\n")
+		}
+		writeRow(headers, "DESCRIPTION")
+
+		for i := range group.fields {
+			f := &group.fields[i]
+			cells := make([]string, len(columns))
+			for c := range columns {
+				cells[c] = columns[c].value(f)
+			}
+
+			// The details follow the description, in parentheses.
+			description := f.description
+			var notes []string
+			for _, d := range f.details {
+				notes = append(notes, d[0]+": "+d[1])
+			}
+			if len(notes) > 0 {
+				description = strings.TrimSpace(description + " (" + strings.Join(notes, "; ") + ")")
+			}
+			writeRow(cells, description)
+		}
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// wrapText splits the text into lines of at most width characters, breaking at spaces. Words longer than
+// the width are kept on their own lines. A width of zero or less disables wrapping.
+func wrapText(text string, width int) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return nil
+	}
+	if width <= 0 {
+		return []string{strings.Join(words, " ")}
+	}
+
+	var lines []string
+	line := words[0]
+	for _, word := range words[1:] {
+		if len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = word
+			continue
+		}
+		line += " " + word
+	}
+
+	return append(lines, line)
+}
+
+// describeType returns a human-readable name of the type of a configuration field.
+func describeType(t reflect.Type) string {
+	switch t {
+	case reflect.TypeOf(time.Duration(0)):
+		return "Duration"
+	case reflect.TypeOf(time.Time{}):
+		return "Time"
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "True or False"
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return "Integer"
+	case reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return "Unsigned Integer"
+	case reflect.Float32, reflect.Float64:
+		return "Float"
+	case reflect.Slice:
+		return "List"
+	case reflect.Map:
+		return "Map"
+	}
+
+	return "String" // default type as string.
 }
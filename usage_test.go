@@ -1,8 +1,10 @@
 package mageconfig
 
 import (
+	"bytes"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -49,3 +51,112 @@ func TestIsHelpRequested(t *testing.T) {
 		})
 	}
 }
+
+type helpTestConfig struct {
+	URL       string        `file:"dbURL" env:"DB_URL" arg:"db-url" required:"true" group:"Database" desc:"Database URL used by the application to store its state"`
+	BackupURL string        `file:"backupDBURL" arg:"backup-db-url" default:"${field:URL}/backup" depends:"URL" group:"Database"`
+	APIKey    string        `env:"API_KEY" arg:"api-key" default:"abc" secret:"true" sources:"env,arg" desc:"API Key"`
+	Timeout   time.Duration `file:"timeout" sources:"file" default:"5s" min:"1s" desc:"Timeout duration"`
+	Mode      string        `arg:"mode" enum:"dev,prod"`
+}
+
+func TestPrintHelp(t *testing.T) {
+	// Beware: this modifies global state and is not safe for parallel test execution.
+	os.Args = []string{"prog"}
+	t.Setenv(profileEnv, "")
+
+	testCases := []struct {
+		name     string
+		opts     []HelpOption
+		expected string
+	}{
+		{
+			name: "verbose",
+			opts: []HelpOption{WithHelpWidth(40)},
+			expected: "" +
+				"Usage of prog\n" +
+				"\n" +
+				"This application is configured via the config file, environment variables, or command-line arguments.\n" +
+				"The following configurations can be used:\n" +
+				"\n" +
+				"  --api-key\n" +
+				"      API Key\n" +
+				"      env:      API_KEY\n" +
+				"      type:     String\n" +
+				"      default:  ******\n" +
+				"      sources:  env, arg\n" +
+				"      secret:   true\n" +
+				"\n" +
+				"  Timeout\n" +
+				"      Timeout duration\n" +
+				"      file:     timeout\n" +
+				"      type:     Duration\n" +
+				"      default:  5s\n" +
+				"      range:    min 1s\n" +
+				"      sources:  file\n" +
+				"\n" +
+				"  --mode\n" +
+				"      type:     String\n" +
+				"      allowed:  dev, prod\n" +
+				"\n" +
+				"Database:\n" +
+				"  --db-url\n" +
+				"      Database URL used by the\n" +
+				"      application to store its state\n" +
+				"      file:     dbURL\n" +
+				"      env:      DB_URL\n" +
+				"      type:     String\n" +
+				"      required: true\n" +
+				"\n" +
+				"  --backup-db-url\n" +
+				"      file:     backupDBURL\n" +
+				"      type:     String\n" +
+				"      default:  ${field:URL}/backup (computed)\n" +
+				"      depends:  URL\n" +
+				"\n",
+		},
+		{
+			name: "compact with templates",
+			opts: []HelpOption{
+				WithHelpLayout(HelpCompact),
+				WithHelpWidth(120),
+				WithHelpHeader("{{.Program}} settings:"),
+				WithHelpFooter("See the README of {{.Program}}."),
+			},
+			expected: "" +
+				"prog settings:\n" +
+				"\n" +
+				"  ARGUMENT         ENV      FILE         TYPE      DEFAULT                         DESCRIPTION\n" +
+				"  --api-key        API_KEY               String    ******                          API Key (sources: env, arg; secret:\n" +
+				"                                                                                   true)\n" +
+				"  Timeout                   timeout      Duration  5s                              Timeout duration (range: min 1s;\n" +
+				"                                                                                   sources: file)\n" +
+				"  --mode                                 String                                    (allowed: dev, prod)\n" +
+				"\n" +
+				"Database:\n" +
+				"  ARGUMENT         ENV      FILE         TYPE      DEFAULT                         DESCRIPTION\n" +
+				"  --db-url         DB_URL   dbURL        String                                    Database URL used by the application\n" +
+				"                                                                                   to store its state (required: true)\n" +
+				"  --backup-db-url           backupDBURL  String    ${field:URL}/backup (computed)  (depends: URL)\n" +
+				"\n" +
+				"See the README of prog.\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.NoError(t, PrintHelp(&buf, &helpTestConfig{}, tc.opts...))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func TestTerminalWidth(t *testing.T) {
+	// The standard output and error of tests are not terminals, so the width comes from COLUMNS.
+	t.Setenv("COLUMNS", "120")
+	assert.Equal(t, 120, terminalWidth())
+
+	t.Setenv("COLUMNS", "")
+	assert.Equal(t, defaultHelpWidth, terminalWidth())
+}
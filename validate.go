@@ -0,0 +1,70 @@
+package mageconfig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrValidationFailed is the error returned when the Validate method of a configuration struct fails.
+var ErrValidationFailed = errors.New("validation failed")
+
+// Validator is an optional interface that configuration structs (and structs nested in them) can implement
+// to check rules spanning several fields, such as "MinReplicas <= MaxReplicas". Validate is called after
+// all required and dependent parameters have been checked.
+type Validator interface {
+	Validate() error
+}
+
+// validate calls the Validate method of every nested struct and then of the configuration struct itself.
+// The returned error wraps both ErrValidationFailed and the error returned by Validate.
+func validate(cfg Config) error {
+	return validateValue(reflect.ValueOf(cfg), "")
+}
+
+// validateValue recursively validates the struct pointed to by v. The path is used to point to
+// the nested struct in error messages.
+func validateValue(v reflect.Value, path string) error {
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	// Validate the nested structs first, so that the parent can rely on their consistency.
+	elem := v.Elem()
+	for i := 0; i < elem.NumField(); i++ {
+		field := elem.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		value := elem.Field(i)
+		if value.Kind() == reflect.Struct {
+			value = value.Addr()
+		}
+		if err := validateValue(value, joinPath(path, field.Name)); err != nil {
+			return err
+		}
+	}
+
+	validator, ok := v.Interface().(Validator)
+	if !ok {
+		return nil
+	}
+	if err := validator.Validate(); err != nil {
+		if path == "" {
+			return fmt.Errorf("%w: %w", ErrValidationFailed, err)
+		}
+		return fmt.Errorf("%w: %s: %w", ErrValidationFailed, path, err)
+	}
+
+	return nil
+}
+
+// joinPath joins the path of a parent struct and the name of its field with a dot.
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
@@ -0,0 +1,80 @@
+package mageconfig
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testTLSConfig struct {
+	Cert string
+	Key  string
+}
+
+func (c *testTLSConfig) Validate() error {
+	if (c.Cert == "") != (c.Key == "") {
+		return errors.New("cert and key must be set together")
+	}
+	return nil
+}
+
+type testValidatorConfig struct {
+	MinReplicas int `arg:"min-replicas" default:"1"`
+	MaxReplicas int `arg:"max-replicas" default:"3"`
+	TLS         testTLSConfig
+}
+
+func (c *testValidatorConfig) Validate() error {
+	if c.MinReplicas > c.MaxReplicas {
+		return errors.New("min-replicas must not exceed max-replicas")
+	}
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		cfg     testValidatorConfig
+		wantErr string
+	}{
+		{
+			name: "valid configuration",
+			cfg:  testValidatorConfig{MinReplicas: 2, MaxReplicas: 2, TLS: testTLSConfig{Cert: "cert", Key: "key"}},
+		},
+		{
+			name:    "invalid configuration",
+			cfg:     testValidatorConfig{MinReplicas: 4, MaxReplicas: 3},
+			wantErr: "validation failed: min-replicas must not exceed max-replicas",
+		},
+		{
+			name:    "invalid nested configuration",
+			cfg:     testValidatorConfig{MinReplicas: 5, MaxReplicas: 3, TLS: testTLSConfig{Cert: "cert"}},
+			wantErr: "validation failed: TLS: cert and key must be set together",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validate(&tc.cfg)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrValidationFailed)
+			assert.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestLoadValidate(t *testing.T) {
+	// Beware: this modifies global state and is not safe for parallel test execution.
+	os.Args = []string{"cmd", "-min-replicas=4"}
+	defer func() { isLoaded = false }()
+
+	cfg := testValidatorConfig{}
+	err := Load(&cfg, "")
+	assert.ErrorIs(t, err, ErrValidationFailed)
+	assert.EqualError(t, err, "validation failed: min-replicas must not exceed max-replicas")
+}
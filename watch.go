@@ -0,0 +1,162 @@
+package mageconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotLoaded is the error returned when a configuration was expected to be loaded by Load.
+var ErrNotLoaded = errors.New("config not loaded")
+
+// WatchInterval is the interval at which Watch checks the configuration files for changes.
+var WatchInterval = time.Second
+
+// configMu guards the values of the configurations updated by Watch.
+var configMu sync.RWMutex
+
+// WatchOption configures how Watch reloads the configuration.
+type WatchOption func(*watchOptions)
+
+// watchOptions holds the settings of a single Watch call.
+type watchOptions struct {
+	onError func(error)
+}
+
+// WithWatchErrors makes Watch call onError with the error of each reload that fails, e.g. because the
+// changed configuration file cannot be parsed or a required parameter is no longer set.
+func WithWatchErrors(onError func(error)) WatchOption {
+	return func(o *watchOptions) {
+		o.onError = onError
+	}
+}
+
+// Watch keeps a configuration loaded by Load up to date with its configuration files, until the context
+// is done. When the configuration file or a profile file changes, the configuration is loaded again, with
+// the same options and command-line arguments, into a fresh copy, which is checked and validated as by Load.
+// Only if it is valid and differs from the current configuration, it replaces the current one, and onChange
+// is called with copies of the old and new configurations. Invalid changes are ignored, keeping the current
+// configuration until the files are fixed; their errors are only reported with the WithWatchErrors option.
+//
+// Watch blocks until the context is done, and returns the context error; it is usually run in a goroutine.
+// The fields of the configuration are replaced in place, so they must be read with Snapshot while Watch is
+// running.
+func Watch(ctx context.Context, cfg Config, onChange func(old, new Config), opts ...WatchOption) error {
+	report := lookupReport(cfg)
+	if report == nil {
+		return ErrNotLoaded
+	}
+
+	wo := &watchOptions{}
+	for _, opt := range opts {
+		opt(wo)
+	}
+
+	stamp := filesStamp(report)
+	ticker := time.NewTicker(WatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		current := filesStamp(report)
+		if current == stamp {
+			continue
+		}
+		stamp = current
+
+		newReport, err := reload(cfg, report, onChange)
+		if err != nil {
+			if wo.onError != nil {
+				wo.onError(err)
+			}
+			continue
+		}
+		report = newReport
+	}
+}
+
+// reload loads the configuration again as described by its report, and replaces the configuration
+// if the new one is valid and different. It returns the report of the new configuration.
+func reload(cfg Config, report *loadReport, onChange func(old, new Config)) (*loadReport, error) {
+	fresh := copyConfig(report.initial)
+	isSet, freshReport, err := readSources(fresh, report.file, report.options)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRequiredAndDepends(fresh, isSet); err != nil {
+		return nil, err
+	}
+	if err := validate(fresh); err != nil {
+		return nil, err
+	}
+	freshReport.initial = report.initial
+
+	configMu.Lock()
+	old := copyConfig(cfg)
+	changed := !reflect.DeepEqual(old, fresh)
+	if changed {
+		reflect.ValueOf(cfg).Elem().Set(reflect.ValueOf(fresh).Elem())
+	}
+	configMu.Unlock()
+
+	storeReport(cfg, freshReport)
+	if changed && onChange != nil {
+		onChange(old, fresh)
+	}
+
+	return freshReport, nil
+}
+
+// filesStamp returns a string that changes whenever one of the configuration files of the report is
+// created, modified or removed.
+func filesStamp(report *loadReport) string {
+	file := report.file
+	if report.options.searchFile {
+		file = findConfigFile(file)
+	}
+
+	var b strings.Builder
+	for _, f := range configFiles(file, report.options.profiles) {
+		info, err := os.Stat(f)
+		if err != nil {
+			fmt.Fprintf(&b, "%s:-;", f)
+			continue
+		}
+		fmt.Fprintf(&b, "%s:%d:%d;", f, info.ModTime().UnixNano(), info.Size())
+	}
+
+	return b.String()
+}
+
+// Snapshot returns a copy of the configuration. Unlike reading the configuration directly, it is safe
+// to call while Watch may replace the configuration.
+func Snapshot(cfg Config) Config {
+	configMu.RLock()
+	defer configMu.RUnlock()
+
+	return copyConfig(cfg)
+}
+
+// copyConfig returns a shallow copy of the configuration struct, or the configuration itself if it is not
+// a pointer to a struct.
+func copyConfig(cfg Config) Config {
+	cfgValue := reflect.ValueOf(cfg)
+	if cfgValue.Kind() != reflect.Pointer || cfgValue.IsNil() || cfgValue.Elem().Kind() != reflect.Struct {
+		return cfg
+	}
+
+	cp := reflect.New(cfgValue.Elem().Type())
+	cp.Elem().Set(cfgValue.Elem())
+
+	return cp.Interface()
+}
@@ -0,0 +1,81 @@
+package mageconfig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatch(t *testing.T) {
+	type testConfig struct {
+		URL     string `file:"url" required:"true"`
+		Retries int    `file:"retries" default:"3"`
+		Debug   bool   `arg:"debug"`
+	}
+
+	file := filepath.Join(t.TempDir(), "mage.config")
+	assert.NoError(t, os.WriteFile(file, []byte("url: https://example.com\n"), 0o644))
+
+	// Beware: this modifies global state and is not safe for parallel test execution.
+	os.Args = []string{"cmd", "--debug"}
+	defer func() { isLoaded = false }()
+	defer func(interval time.Duration) { WatchInterval = interval }(WatchInterval)
+	WatchInterval = 10 * time.Millisecond
+
+	cfg := testConfig{}
+	assert.NoError(t, Load(&cfg, file))
+	// The arguments are removed after the first load, but still apply when reloading.
+	os.Args = []string{"cmd"}
+
+	changes := make(chan [2]testConfig, 1)
+	errs := make(chan error, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- Watch(ctx, &cfg, func(old, new Config) {
+			changes <- [2]testConfig{*old.(*testConfig), *new.(*testConfig)}
+		}, WithWatchErrors(func(err error) { errs <- err }))
+	}()
+
+	time.Sleep(5 * WatchInterval) // Let Watch record the initial state of the files.
+
+	// An invalid configuration is ignored, and its error is reported.
+	assert.NoError(t, os.WriteFile(file, []byte("retries: 5\n"), 0o644))
+	select {
+	case <-changes:
+		t.Fatal("invalid configuration swapped in")
+	case err := <-errs:
+		assert.ErrorIs(t, err, ErrRequiredNotSet)
+	case <-time.After(time.Second):
+		t.Fatal("reload error not reported")
+	}
+	assert.Equal(t, testConfig{URL: "https://example.com", Retries: 3, Debug: true}, *Snapshot(&cfg).(*testConfig))
+
+	// A valid configuration replaces the current one.
+	assert.NoError(t, os.WriteFile(file, []byte("url: https://example.org\nretries: 5\n"), 0o644))
+	select {
+	case change := <-changes:
+		assert.Equal(t, testConfig{URL: "https://example.com", Retries: 3, Debug: true}, change[0])
+		assert.Equal(t, testConfig{URL: "https://example.org", Retries: 5, Debug: true}, change[1])
+	case <-time.After(time.Second):
+		t.Fatal("configuration change not notified")
+	}
+	assert.Equal(t, testConfig{URL: "https://example.org", Retries: 5, Debug: true}, *Snapshot(&cfg).(*testConfig))
+	assert.Equal(t, Origin{Source: SourceFile, Location: file + ":2"}, Origins(&cfg)["Retries"])
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestWatchNotLoaded(t *testing.T) {
+	type testConfig struct {
+		URL string `file:"url"`
+	}
+
+	cfg := testConfig{}
+	assert.ErrorIs(t, Watch(context.Background(), &cfg, nil), ErrNotLoaded)
+}